@@ -0,0 +1,125 @@
+// Shot clocks. With NewGamePayload.ShotClockMs set, every seat, human or not, gets that
+// long to act each turn, then draws on its time bank; when both are gone the seat is
+// checked or folded (autoAct) and a running decision is cancelled. Banks are used up
+// across turns and topped back up at the start of each level. There are no blind
+// levels, so a level is TimeBankLevelHands hands.
+//
+// While a seat's clock runs, clients get a clock_tick every second. Ticks are not
+// numbered or kept for resync (resync.go), and spectators behind a delay don't get them.
+package api
+
+import (
+	"log"
+	"time"
+)
+
+const defaultLevelHands = 10
+
+// turnClock is the clock of the seat to act. It belongs to one turn: any change to the
+// game (version) stops it.
+type turnClock struct {
+	version int
+	seat    int
+	started time.Time
+}
+
+// refillBanks tops every time bank back up at the start of a level.
+func (r *room) refillBanks() {
+	if r.timeBank == 0 || (r.gs.HandNumber-1)%r.levelHands != 0 {
+		return
+	}
+	for i := range r.banks {
+		r.banks[i] = r.timeBank
+	}
+	if r.gs.HandNumber > 1 {
+		log.Printf("Game %s: time banks refilled for hand #%d", r.id, r.gs.HandNumber)
+	}
+}
+
+// startClock starts the current seat's clock unless it is already running for this turn.
+func (r *room) startClock() {
+	if r.shotClock == 0 || (r.turn != nil && r.turn.version == r.version) {
+		return
+	}
+	r.turn = &turnClock{version: r.version, seat: r.gs.CurrentPlayerIdx, started: time.Now()}
+	r.tick(r.version)
+}
+
+// stopClock stops the running clock, charging its seat for the bank time it used.
+func (r *room) stopClock() {
+	if r.turn == nil {
+		return
+	}
+	_, bank := r.remaining()
+	r.banks[r.turn.seat] = bank
+	r.turn = nil
+}
+
+// remaining is what is left of the running clock's shot clock and time bank.
+func (r *room) remaining() (shot, bank time.Duration) {
+	shot = r.shotClock - time.Since(r.turn.started)
+	bank = r.banks[r.turn.seat]
+	if shot < 0 {
+		bank += shot
+		shot = 0
+	}
+	return shot, max(bank, 0)
+}
+
+// timeoutMs is how long the seat to act has left in all, 0 without a clock.
+func (r *room) timeoutMs() int {
+	if r.turn == nil || r.turn.version != r.version {
+		return 0
+	}
+	shot, bank := r.remaining()
+	return int((shot + bank).Milliseconds())
+}
+
+// tick broadcasts the clock of the turn at version and schedules the next tick, or acts
+// for the seat once its time is up.
+func (r *room) tick(version int) {
+	if r.turn == nil || r.turn.version != version {
+		return
+	}
+	if !r.watched() {
+		// Nobody is watching: next starts a fresh clock when someone is
+		r.turn = nil
+		return
+	}
+
+	seat := r.turn.seat
+	shot, bank := r.remaining()
+	if shot+bank == 0 {
+		r.banks[seat] = 0
+		r.turn = nil
+		log.Printf("Game %s: seat %d ran out of time", r.id, seat)
+		r.interrupt()
+		r.autoAct(seat, "timeout")
+		return
+	}
+
+	r.sendLive(ServerMessage{
+		Type: MsgClockTick,
+		Payload: ClockTickPayload{
+			PlayerIdx:   seat,
+			RemainingMs: int(shot.Milliseconds()),
+			TimeBankMs:  int(bank.Milliseconds()),
+		},
+	})
+	time.AfterFunc(min(time.Second, shot+bank), func() {
+		r.do(func() { r.tick(version) })
+	})
+}
+
+// sendLive sends msg to the owner and every spectator that sees the game live, without
+// numbering it.
+func (r *room) sendLive(msg ServerMessage) {
+	if r.owner != nil {
+		r.owner.send(msg)
+	}
+	for c, admin := range r.spectators {
+		if admin || r.spectatorDelay == 0 {
+			c.send(msg)
+		}
+	}
+}
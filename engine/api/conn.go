@@ -0,0 +1,107 @@
+// One WebSocket connection. gorilla/websocket allows only one writer at a time, and a
+// connection is written to both from its own read loop (errors, replies) and from its
+// game's room goroutine, so every message goes through wsConn.send onto a queue that the
+// connection's writer goroutine drains. send never blocks: a client that falls
+// sendQueue messages behind is disconnected rather than holding up its room, and can
+// rejoin or resync from where it left off.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	sendQueue    = 256
+	writeTimeout = 10 * time.Second
+)
+
+type wsConn struct {
+	conn *websocket.Conn
+	out  chan []byte   // Encoded messages for writer
+	done chan struct{} // Closed by close
+	once sync.Once
+}
+
+func newWSConn(conn *websocket.Conn) *wsConn {
+	c := &wsConn{
+		conn: conn,
+		out:  make(chan []byte, sendQueue),
+		done: make(chan struct{}),
+	}
+	go c.writer()
+	return c
+}
+
+func (c *wsConn) send(msg ServerMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+
+	select {
+	case c.out <- data:
+	case <-c.done:
+	default:
+		log.Printf("Disconnecting %s: %d messages behind", c.conn.RemoteAddr(), sendQueue)
+		c.close()
+	}
+}
+
+func (c *wsConn) writer() {
+	for {
+		select {
+		case data := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				log.Printf("Error sending message: %v", err)
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// close stops the writer and closes the socket, which ends the connection's read loop
+// so it leaves its game.
+func (c *wsConn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+func (c *wsConn) sendError(message string) {
+	log.Printf("Error: %s", message)
+	c.send(ServerMessage{
+		Type:    MsgError,
+		Payload: ErrorPayload{Message: message},
+	})
+}
+
+// sendErrorCode sends an error the client can tell apart by code, see ErrorPayload.
+func (c *wsConn) sendErrorCode(code, message string) {
+	log.Printf("Error (%s): %s", code, message)
+	c.send(ServerMessage{
+		Type:    MsgError,
+		Payload: ErrorPayload{Message: message, Code: code},
+	})
+}
+
+// replyError sends err, with its code if it has one.
+func (c *wsConn) replyError(err error) {
+	var ce *codedError
+	if errors.As(err, &ce) {
+		c.sendErrorCode(ce.code, ce.message)
+		return
+	}
+	c.sendError(err.Error())
+}
@@ -0,0 +1,23 @@
+// Who decides for each seat of a game. handleNewGame gives each game's room a
+// decider.Registry; the room asks it for every seat that is not a human's.
+package api
+
+import (
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// newDeciders seats humans and LLMs according to the game mode: the user's seat in
+// ModePlay and every seat in ModeTest are human, everything else is an LLM.
+func newDeciders(gs *game.GameState) *decider.Registry {
+	reg := decider.NewRegistry()
+	for i := range gs.Players {
+		switch {
+		case gs.Mode == game.ModeTest, gs.Mode == game.ModePlay && i == gs.UserSeatIdx:
+			reg.Set(i, decider.Human{})
+		default:
+			reg.Set(i, decider.LLM{Mode: gs.Mode.String()})
+		}
+	}
+	return reg
+}
@@ -1,26 +1,43 @@
-// Handles game actions from the frontend: new_game, start_hand, action, pause, resume.
-// Called by server.go when it receives these message types.
+// Handles game actions from the frontend: new_game, join, start_hand, action, pause,
+// resume, and the test mode debugging commands undo and rewind.
+// Called by server.go when it receives these message types; everything after new_game
+// runs on the game's room goroutine (room.go).
 package api
 
 import (
+	"fmt"
 	"log"
 	"time"
 
-	"github.com/gorilla/websocket"
+	"github.com/rizzwareengineer/no-LLMit/engine/dataset"
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
 	"github.com/rizzwareengineer/no-LLMit/engine/game"
 )
 
-func (s *Server) handleNewGame(conn *websocket.Conn, payload interface{}) {
+func (s *Server) handleNewGame(c *wsConn, payload interface{}) {
 	ngp, err := parsePayload[NewGamePayload](payload)
 	if err != nil {
-		s.sendError(conn, "Invalid new game payload")
+		c.sendError("Invalid new game payload")
 		return
 	}
 
-	if len(ngp.PlayerNames) < 2 || len(ngp.PlayerNames) > 9 {
-		s.sendError(conn, "Player count must be between 2 and 9")
+	r, err := s.createGame(ngp, c)
+	if err != nil {
+		c.sendError(err.Error())
 		return
 	}
+	s.register(c, r)
+
+	r.do(r.sendSession)
+	r.do(r.determineButton)
+}
+
+// createGame builds a game from ngp and starts its room, owned by owner (nil when the
+// game is created over HTTP, see rest.go). The caller adds it to the server.
+func (s *Server) createGame(ngp *NewGamePayload, owner *wsConn) (*room, error) {
+	if len(ngp.PlayerNames) < 2 || len(ngp.PlayerNames) > 9 {
+		return nil, fmt.Errorf("Player count must be between 2 and 9")
+	}
 
 	if ngp.StartingStack <= 0 {
 		ngp.StartingStack = 2000
@@ -41,118 +58,197 @@ func (s *Server) handleNewGame(conn *websocket.Conn, payload interface{}) {
 		},
 		Mode:        ParseGameMode(ngp.Mode),
 		UserSeatIdx: ngp.UserSeatIdx,
+		UserName:    ngp.UserName,
+		Prompt:      ngp.Prompt,
 	}
 
 	gs := game.NewGame(config)
 
+	deciders := newDeciders(gs)
+	for seat, name := range ngp.Bots {
+		bot, err := decider.NewBot(name)
+		if err != nil || seat < 0 || seat >= len(gs.Players) {
+			return nil, fmt.Errorf("Invalid bot %q for seat %d", name, seat)
+		}
+		deciders.Set(seat, bot)
+	}
+
+	r := s.newRoom(gs, deciders, owner, roomOptions{
+		spectatorDelay: time.Duration(ngp.SpectatorDelayMs) * time.Millisecond,
+		shotClock:      time.Duration(ngp.ShotClockMs) * time.Millisecond,
+		timeBank:       time.Duration(ngp.TimeBankMs) * time.Millisecond,
+		levelHands:     ngp.TimeBankLevelHands,
+	})
+
+	log.Printf("Created new game: %s with %d players in %s mode", gs.ID, len(gs.Players), gs.Mode.String())
+	return r, nil
+}
+
+// handleJoin makes c a spectator of an existing game: it gets everything the game
+// broadcasts, with hole cards hidden per visibility.go, but can't act, start hands or
+// pause. Joining with the server's ADMIN_TOKEN shows every card instantly.
+func (s *Server) handleJoin(c *wsConn, payload interface{}) {
+	jp, err := parsePayload[JoinPayload](payload)
+	if err != nil {
+		c.sendError("Invalid join payload")
+		return
+	}
+
+	s.mu.RLock()
+	r := s.games[jp.GameID]
+	s.mu.RUnlock()
+	if r == nil {
+		c.sendError(fmt.Sprintf("Game %s not found", jp.GameID))
+		return
+	}
+
+	admin := s.isAdmin(jp.Token)
+	if jp.Token != "" && !admin {
+		c.sendError("Invalid admin token")
+		return
+	}
+
+	s.leave(c)
 	s.mu.Lock()
-	s.games[gs.ID] = gs
-	s.clients[conn] = gs.ID
+	s.clients[c] = jp.GameID
 	s.mu.Unlock()
 
-	log.Printf("Created new game: %s with %d players in %s mode", gs.ID, len(gs.Players), gs.Mode.String())
+	r.do(func() {
+		watched := r.watched()
+		r.spectators[c] = admin
+		log.Printf("Spectator joined game %s (%d watching, admin: %v)", r.id, len(r.spectators), admin)
+
+		c.send(ServerMessage{
+			Type: MsgJoined,
+			Payload: JoinedPayload{
+				GameID:     r.id,
+				Spectators: len(r.spectators),
+				Admin:      admin,
+				DelayMs:    int(r.spectatorDelay.Milliseconds()),
+			},
+		})
+		c.send(r.spectatorState(admin))
+
+		// The game may have been waiting for someone to watch it
+		if !watched {
+			r.next()
+		}
+	})
+}
 
+// determineButton deals each player a card for the button and reveals them to the
+// frontend one every 2 seconds.
+func (r *room) determineButton() {
 	// Send initial game state immediately so UI shows players
-	s.sendGameState(conn, gs)
+	r.sendGameState()
 
-	// Determine button by dealing cards to each player
-	buttonCards := gs.DetermineButton()
-	
-	// Send each card with 2 second delay
+	buttonCards := r.gs.DetermineButton()
+	buttonIdx := r.gs.ButtonIdx
+	winnerName := r.gs.Players[buttonIdx].Name
+
+	// Sleep off the room goroutine so actions and state requests aren't held up
 	go func() {
 		for _, bc := range buttonCards {
-			// Check if connection is still valid
-			s.mu.RLock()
-			_, connected := s.clients[conn]
-			s.mu.RUnlock()
-			if !connected {
-				log.Printf("Connection closed during button determination, stopping")
-				return
-			}
-			
-			s.send(conn, ServerMessage{
-				Type: MsgButtonCard,
-				Payload: ButtonCardPayload{
-					PlayerIdx:  bc.PlayerIdx,
-					PlayerName: bc.PlayerName,
-					Card:       bc.Card,
-				},
+			r.do(func() {
+				r.send(ServerMessage{
+					Type: MsgButtonCard,
+					Payload: ButtonCardPayload{
+						PlayerIdx:  bc.PlayerIdx,
+						PlayerName: bc.PlayerName,
+						Card:       bc.Card,
+					},
+				})
 			})
 			time.Sleep(2 * time.Second)
 		}
-		
-		// Check if still connected
-		s.mu.RLock()
-		_, connected := s.clients[conn]
-		s.mu.RUnlock()
-		if !connected {
-			return
-		}
-		
+
 		// Announce the winner
-		winnerName := gs.Players[gs.ButtonIdx].Name
-		log.Printf("Button goes to: %s (seat %d)", winnerName, gs.ButtonIdx)
-		
-		s.send(conn, ServerMessage{
-			Type: MsgButtonWinner,
-			Payload: ButtonWinnerPayload{
-				PlayerIdx:  gs.ButtonIdx,
-				PlayerName: winnerName,
-			},
+		r.do(func() {
+			log.Printf("Button goes to: %s (seat %d)", winnerName, buttonIdx)
+			r.send(ServerMessage{
+				Type: MsgButtonWinner,
+				Payload: ButtonWinnerPayload{
+					PlayerIdx:  buttonIdx,
+					PlayerName: winnerName,
+				},
+			})
 		})
-		
+
 		// Wait 5 seconds for user to read who gets the button
 		time.Sleep(5 * time.Second)
-		
-		s.mu.RLock()
-		_, connected = s.clients[conn]
-		s.mu.RUnlock()
-		if !connected {
-			return
-		}
-		
-		s.sendGameState(conn, gs)
+		r.do(r.sendGameState)
 	}()
 }
 
-func (s *Server) handleStartHand(conn *websocket.Conn) {
-	gs := s.getGameForConn(conn)
-	if gs == nil {
-		s.sendError(conn, "No game found. Create a game first.")
-		return
+func (r *room) handleStartHand(c *wsConn) {
+	if err := r.startHand(); err != nil {
+		c.sendError(err.Error())
 	}
+}
 
+func (r *room) startHand() error {
+	gs := r.gs
 	if err := gs.StartHand(); err != nil {
-		s.sendError(conn, err.Error())
-		return
+		return err
 	}
+	r.version++
+	r.llmReady = false
+	r.actionIDs = make(map[string]bool)
+	r.refillBanks()
+	r.interrupt()
 
 	log.Printf("Started hand #%d", gs.HandNumber)
 
-	s.send(conn, ServerMessage{
+	r.send(ServerMessage{
 		Type: MsgHandStart,
 		Payload: map[string]interface{}{
 			"handNumber": gs.HandNumber,
 		},
 	})
 
-	s.sendGameState(conn, gs)
-
-	go s.handleLLMTurns(conn, gs)
+	r.sendGameState()
+	r.next()
+	return nil
 }
 
-func (s *Server) handleAction(conn *websocket.Conn, payload interface{}) {
-	gs := s.getGameForConn(conn)
-	if gs == nil {
-		s.sendError(conn, "No game found")
-		return
-	}
-
+func (r *room) handleAction(c *wsConn, payload interface{}) {
 	ap, err := parsePayload[ActionPayload](payload)
 	if err != nil {
-		s.sendError(conn, "Invalid action payload")
+		c.sendError("Invalid action payload")
 		return
 	}
+	if err := r.act(ap); err != nil {
+		c.replyError(err)
+	}
+}
+
+// codedError is an error the client gets with an ErrorPayload code.
+type codedError struct {
+	code    string
+	message string
+}
+
+func (e *codedError) Error() string { return e.message }
+
+// act plays a human seat's action. Seats played by a bot or an LLM can't be acted for.
+func (r *room) act(ap *ActionPayload) error {
+	gs := r.gs
+
+	if !r.isHuman(ap.PlayerIdx) {
+		return fmt.Errorf("Only human seats can be acted for")
+	}
+
+	// A retried or late click must not act on a later turn. The version is bumped when
+	// each hand starts, so a missing turn token never matches.
+	if ap.ActionID != "" && r.actionIDs[ap.ActionID] {
+		return &codedError{ErrDuplicateAction, fmt.Sprintf("Action %s was already applied", ap.ActionID)}
+	}
+	if ap.TurnToken == 0 {
+		return &codedError{ErrStaleAction, "The action needs the turnToken from action_required or the game state"}
+	}
+	if (ap.HandNumber != 0 && ap.HandNumber != gs.HandNumber) || ap.TurnToken != r.version {
+		return &codedError{ErrStaleAction, "That turn is over"}
+	}
 
 	action := game.Action{
 		Type:      ParseActionType(ap.Action),
@@ -160,89 +256,141 @@ func (s *Server) handleAction(conn *websocket.Conn, payload interface{}) {
 		PlayerIdx: ap.PlayerIdx,
 	}
 
-	if err := gs.ProcessAction(action); err != nil {
-		s.sendError(conn, err.Error())
-		return
+	// Capture what the user saw before acting, for "play like me" fine-tuning data
+	var record *dataset.Record
+	if gs.Mode == game.ModePlay && ap.PlayerIdx == gs.UserSeatIdx && ap.PlayerIdx == gs.CurrentPlayerIdx {
+		record = r.s.humanDecisionRecord(gs, ap)
 	}
 
-	log.Printf("Player %d: %s %d", ap.PlayerIdx, ap.Action, ap.Amount)
+	if err := gs.ProcessAction(action); err != nil {
+		return err
+	}
+	r.version++
+	if ap.ActionID != "" {
+		r.actionIDs[ap.ActionID] = true
+	}
 
-	if gs.NeedToAdvanceStreet() {
-		if err := gs.AdvanceStreet(); err != nil {
-			s.sendError(conn, err.Error())
-			return
+	if record != nil {
+		last := gs.Players[ap.PlayerIdx].LastAction
+		if last != nil {
+			record.ExecutedAction = last.Type.String()
+			record.ExecutedAmount = last.Amount
 		}
-
-		s.send(conn, ServerMessage{
-			Type: MsgStreetChange,
-			Payload: map[string]interface{}{
-				"street": gs.Street.String(),
-			},
-		})
+		r.s.decisions.Add(*record)
 	}
 
-	if gs.IsHandComplete() {
-		gs.EliminateBrokePlayers()
-
-		s.send(conn, ServerMessage{
-			Type: MsgHandComplete,
-			Payload: HandCompletePayload{
-				Winners:    convertWinners(gs.Winners),
-				HandNumber: gs.HandNumber,
-			},
-		})
-	}
+	log.Printf("Player %d: %s %d", ap.PlayerIdx, ap.Action, ap.Amount)
 
-	s.sendGameState(conn, gs)
+	r.settle()
+	return nil
+}
 
-	if !gs.IsHandComplete() {
-		go s.handleLLMTurns(conn, gs)
+// humanDecisionRecord builds a dataset record holding the prompt an LLM in the user's
+// seat would have received right now.
+func (s *Server) humanDecisionRecord(gs *game.GameState, ap *ActionPayload) *dataset.Record {
+	player := gs.Players[ap.PlayerIdx]
+	user := gs.UserName
+	if user == "" {
+		user = player.Name
 	}
-}
 
-func (s *Server) handleGetState(conn *websocket.Conn) {
-	gs := s.getGameForConn(conn)
-	if gs == nil {
-		s.sendError(conn, "No game found")
-		return
+	prompt := gs.GetLLMPromptPayload(player.Name, s.buildLLMValidActions(gs))
+	return &dataset.Record{
+		GameID:       gs.ID,
+		HandNumber:   gs.HandNumber,
+		Source:       dataset.SourceHuman,
+		Model:        player.Name,
+		User:         user,
+		Seat:         ap.PlayerIdx,
+		Street:       gs.Street.String(),
+		Time:         time.Now(),
+		Prompt:       prompt,
+		PromptTokens: prompt.EstimatedTokens,
+		Response: dataset.Response{
+			Action: ap.Action,
+			Amount: ap.Amount,
+		},
+		Legal: true, // Rejected actions are sent back to the user to retry, not recorded
+		Seq:   len(gs.Events),
 	}
+}
 
-	s.sendGameState(conn, gs)
+func (r *room) handleGetState(c *wsConn) {
+	c.send(r.stateFor(c))
 }
 
-func (s *Server) handlePause(conn *websocket.Conn) {
-	gs := s.getGameForConn(conn)
-	if gs == nil {
-		s.sendError(conn, "No game found")
+func (r *room) handlePause(c *wsConn, payload interface{}) {
+	pp, err := parsePayload[PausePayload](payload)
+	if err != nil {
+		c.sendError("Invalid pause payload")
 		return
 	}
 
-	// Set pending pause - backend will stop after current LLM completes
+	// A soft pause lets the decision already running (if any) land; a hard one cancels
+	// it. Either way the next seat isn't asked until resume.
 	// Frontend will finish displaying current player, then pause
-	s.mu.Lock()
-	s.pendingPause[gs.ID] = true
-	s.mu.Unlock()
+	r.paused = true
+	r.stopClock()
+	if pp.Hard {
+		r.interrupt()
+	}
 
-	log.Printf("Game %s pause requested", gs.ID)
+	log.Printf("Game %s pause requested", r.id)
 	// Send paused immediately so frontend knows to stop after current player
-	s.send(conn, ServerMessage{Type: MsgPaused})
+	r.send(ServerMessage{Type: MsgPaused})
+}
+
+func (r *room) handleResume() {
+	wasPaused := r.paused
+	r.paused = false
+
+	log.Printf("Game %s resumed", r.id)
+	r.send(ServerMessage{Type: MsgResumed})
+
+	if wasPaused {
+		r.next()
+	}
 }
 
-func (s *Server) handleResume(conn *websocket.Conn) {
-	gs := s.getGameForConn(conn)
-	if gs == nil {
-		s.sendError(conn, "No game found")
+func (r *room) handleUndo(c *wsConn) {
+	gs := r.gs
+	if gs.Mode != game.ModeTest {
+		c.sendError("Undo is only available in test mode")
 		return
 	}
 
-	s.mu.Lock()
-	s.paused[gs.ID] = false
-	s.pendingPause[gs.ID] = false // Clear any pending pause too
-	s.mu.Unlock()
+	if err := gs.Undo(); err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	r.version++
+	r.interrupt()
+	// The log now ends where the undone action was
+	r.s.decisions.Discard(gs.ID, len(gs.Events))
+
+	log.Printf("Game %s: undid last action", gs.ID)
+	r.sendGameState()
+	r.next()
+}
+
+func (r *room) handleRewind(c *wsConn, payload interface{}) {
+	gs := r.gs
 
-	log.Printf("Game %s resumed", gs.ID)
-	s.send(conn, ServerMessage{Type: MsgResumed})
+	rp, err := parsePayload[RewindPayload](payload)
+	if err != nil {
+		c.sendError("Invalid rewind payload")
+		return
+	}
+
+	if err := gs.RewindTo(rp.Seq); err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	r.version++
+	r.interrupt()
+	r.s.decisions.Discard(gs.ID, len(gs.Events))
 
-	// Don't start a new goroutine here - the existing one waiting in the
-	// pause loop (llm_handlers.go line 24-26) will continue automatically
+	log.Printf("Game %s: rewound to event %d", gs.ID, rp.Seq)
+	r.sendGameState()
+	r.next()
 }
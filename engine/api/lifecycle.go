@@ -0,0 +1,196 @@
+// Game lifecycle. A game is in the lobby until its first hand, then running or paused,
+// and ends finished (one player has every chip, or the owner sent end_game) or abandoned
+// (nobody connected for idleTimeout, nor sent an HTTP request with one of its session
+// tokens). Ending a game broadcasts game_over with its results, which are appended to
+// RESULTS_PATH when set. Idle rooms are dropped from the server; /api/games still lists
+// them from the results.
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type GameStatus string
+
+const (
+	StatusLobby     GameStatus = "lobby"
+	StatusRunning   GameStatus = "running"
+	StatusPaused    GameStatus = "paused"
+	StatusFinished  GameStatus = "finished"
+	StatusAbandoned GameStatus = "abandoned"
+)
+
+const (
+	idleTimeout  = 30 * time.Minute
+	reapInterval = time.Minute
+)
+
+func (r *room) status() GameStatus {
+	switch {
+	case r.result != nil:
+		return GameStatus(r.result.State)
+	case r.paused:
+		return StatusPaused
+	case r.gs.HandNumber == 0:
+		return StatusLobby
+	default:
+		return StatusRunning
+	}
+}
+
+// finish ends the game: nothing is asked of any seat again and control commands are
+// refused. Its results are saved and broadcast.
+func (r *room) finish(status GameStatus, reason string) {
+	if r.result != nil {
+		return
+	}
+	r.end()
+	r.interrupt()
+	r.stopClock()
+	r.s.decisions.Discard(r.id, 0)
+
+	r.result = gameResult(r.gs, status, reason)
+	r.s.results.add(*r.result)
+	log.Printf("Game %s %s (%s) after %d hands", r.id, status, reason, r.gs.HandNumber)
+
+	r.send(ServerMessage{
+		Type:    MsgGameOver,
+		Payload: *r.result,
+	})
+}
+
+func (r *room) handleEndGame() {
+	r.finish(StatusFinished, "ended")
+}
+
+// gameResult is each player's result as of now. A hand cut short by end_game doesn't
+// count: stacks are taken from its start.
+func gameResult(gs *game.GameState, status GameStatus, reason string) *GameResultPayload {
+	var first, current []int
+	for _, ev := range gs.Events {
+		if ev.Type == game.EventHandStart {
+			if first == nil {
+				first = ev.Stacks
+			}
+			current = ev.Stacks
+		}
+	}
+
+	players := make([]PlayerResultPayload, len(gs.Players))
+	for i, p := range gs.Players {
+		stack := p.Stack
+		if current != nil && !gs.IsHandComplete() {
+			stack = current[i]
+		}
+		start := stack
+		if first != nil {
+			start = first[i]
+		}
+		players[i] = PlayerResultPayload{
+			Seat:  i,
+			Name:  p.Name,
+			Stack: stack,
+			Net:   stack - start,
+		}
+	}
+
+	return &GameResultPayload{
+		GameID:    gs.ID,
+		State:     string(status),
+		Reason:    reason,
+		Mode:      gs.Mode.String(),
+		Hands:     gs.HandNumber,
+		StartedAt: gs.GameStartTime.Format(time.RFC3339),
+		EndedAt:   time.Now().Format(time.RFC3339),
+		Players:   players,
+	}
+}
+
+// reap drops rooms nobody has connected to or sent a session token for in idleTimeout,
+// abandoning their game first if it hadn't ended.
+func (s *Server) reap() {
+	for range time.Tick(reapInterval) {
+		for _, r := range s.rooms() {
+			idle := false
+			r.query(func() {
+				idle = r.owner == nil && len(r.spectators) == 0 && time.Since(r.idleSince) > idleTimeout
+				if idle {
+					r.finish(StatusAbandoned, "idle")
+				}
+			})
+			if idle {
+				s.mu.Lock()
+				delete(s.games, r.id)
+				s.mu.Unlock()
+				close(r.closed)
+				log.Printf("Reaped game %s", r.id)
+			}
+		}
+	}
+}
+
+// resultLog keeps every ended game's results, in memory and in a JSON Lines file.
+type resultLog struct {
+	results []GameResultPayload
+	file    *os.File // nil keeps results in memory only
+	mu      sync.Mutex
+}
+
+func newResultLog(path string) (*resultLog, error) {
+	l := &resultLog{}
+	if path == "" {
+		return l, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var result GameResultPayload
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to parse results file: %w", err)
+		}
+		l.results = append(l.results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+	l.file = f
+	return l, nil
+}
+
+func (l *resultLog) add(result GameResultPayload) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = append(l.results, result)
+	if l.file == nil {
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("Error marshaling results: %v", err)
+		return
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		log.Printf("Error saving results for game %s: %v", result.GameID, err)
+	}
+}
+
+func (l *resultLog) all() []GameResultPayload {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]GameResultPayload(nil), l.results...)
+}
@@ -1,173 +1,237 @@
-// Orchestrates LLM turns: calls client/llm.go to get decisions and sends results
-// immediately to frontend. Frontend handles all display timing.
-// Called by game_handlers.go after human actions.
+// Orchestrates LLM and bot turns: asks each seat's decider (LLMs go through
+// client/llm.go) and sends results immediately to frontend. Frontend handles all
+// display timing.
+// Called by the game's room (room.go) whenever a non-human seat is to act.
 package api
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/rizzwareengineer/no-LLMit/engine/client"
+	"github.com/rizzwareengineer/no-LLMit/engine/dataset"
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
 	"github.com/rizzwareengineer/no-LLMit/engine/game"
 )
 
-func (s *Server) handleLLMTurns(conn *websocket.Conn, gs *game.GameState) {
-	if err := client.CheckLLMServiceHealth(); err != nil {
-		log.Printf("!!! LLM service not available: %v", err)
-		s.sendError(conn, "LLM service not available. Please start the Python service (cd llm && python app.py)")
+// pendingDecision is a decision point handed to a decider, kept so its result can be
+// checked and recorded when it comes back to the room.
+type pendingDecision struct {
+	version    int
+	decider    decider.Decider
+	playerIdx  int
+	playerName string
+	street     string
+	payload    *game.LLMPromptPayload
+}
+
+// decide asks a non-human seat's decider for an action off the room goroutine. The
+// result comes back as a command, so the room keeps serving actions and state requests
+// while an LLM thinks.
+func (r *room) decide(d decider.Decider) {
+	gs := r.gs
+	player := gs.GetCurrentPlayer()
+	if player == nil {
 		return
 	}
 
-	for !gs.IsHandComplete() && gs.IsWaitingForAction() {
-		// Check for pending pause - stop before starting next LLM query
-		if s.isPendingPause(gs.ID) {
-			s.mu.Lock()
-			s.paused[gs.ID] = true
-			s.pendingPause[gs.ID] = false
-			s.mu.Unlock()
-			log.Printf("Game %s paused (stopped before next LLM)", gs.ID)
-		}
+	p := pendingDecision{
+		version:    r.version,
+		decider:    d,
+		playerIdx:  gs.CurrentPlayerIdx,
+		playerName: player.Name,
+		street:     gs.Street.String(),
+	}
 
-		// Wait if paused
-		for s.isPaused(gs.ID) {
-			time.Sleep(100 * time.Millisecond)
-		}
+	log.Printf("")
+	log.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	log.Printf("🎯 Requesting decision from: %s", p.playerName)
 
-		// In play mode, stop if it's the human's turn
-		if gs.Mode == game.ModePlay && gs.CurrentPlayerIdx == gs.UserSeatIdx {
-			s.sendActionRequiredIfNeeded(conn, gs)
-			return
-		}
+	// Notify frontend that this player is now deciding
+	r.send(ServerMessage{
+		Type: MsgLLMThinking,
+		Payload: LLMThinkingPayload{
+			PlayerIdx:  p.playerIdx,
+			PlayerName: p.playerName,
+			// No reason yet - frontend will show "Thinking..."
+		},
+	})
 
-		// In test mode, human controls all players
-		if gs.Mode == game.ModeTest {
-			s.sendActionRequiredIfNeeded(conn, gs)
-			return
-		}
-
-		player := gs.GetCurrentPlayer()
-		if player == nil {
-			return
-		}
+	validActions := r.s.buildLLMValidActions(gs)
+	p.payload = gs.GetLLMPromptPayload(p.playerName, validActions)
+	if p.payload == nil {
+		log.Printf("Failed to build LLM payload for %s", p.playerName)
+		r.sendError("Failed to build LLM payload")
+		return
+	}
 
-		playerIdx := gs.CurrentPlayerIdx
-		playerName := player.Name
+	// Bots and humans don't need the Python service
+	checkHealth := d.Kind() == decider.KindLLM && !r.llmReady
 
-		log.Printf("")
-		log.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		log.Printf("🎯 Requesting decision from: %s", playerName)
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.deciding = cancel
 
-		// Notify frontend that this player is now deciding
-		s.send(conn, ServerMessage{
-			Type: MsgLLMThinking,
-			Payload: LLMThinkingPayload{
-				PlayerIdx:  playerIdx,
-				PlayerName: playerName,
-				// No reason yet - frontend will show "Thinking..."
-			},
-		})
+	// finish runs on the room goroutine once the decider is done, and reports whether
+	// its answer still counts: a cancelled decision is dropped and the seat asked again
+	// when the game can go on.
+	finish := func() bool {
+		r.deciding = nil
+		cancelled := ctx.Err() != nil
+		cancel()
+		if cancelled {
+			log.Printf("Decision for %s cancelled", p.playerName)
+			r.next()
+		}
+		return !cancelled
+	}
 
-		validActions := s.buildLLMValidActions(gs)
-		payload := gs.GetLLMPromptPayload(playerName, validActions)
-		if payload == nil {
-			log.Printf("Failed to build LLM payload for %s", playerName)
-			s.sendError(conn, "Failed to build LLM payload")
-			return
+	go func() {
+		if checkHealth {
+			if err := client.CheckLLMServiceHealth(ctx); err != nil {
+				r.do(func() {
+					if finish() {
+						log.Printf("!!! LLM service not available: %v", err)
+						r.sendError("LLM service not available. Please start the Python service (cd llm && python app.py)")
+					}
+				})
+				return
+			}
 		}
 
-		// Call LLM API (blocking - compute as fast as possible)
+		// Ask the seat's decider (blocking - compute as fast as possible)
 		startTime := time.Now()
-		modeStr := gs.Mode.String()
-		decision, err := client.GetLLMDecision(playerName, payload, modeStr)
+		decision, err := d.Decide(ctx, p.payload)
 		apiDuration := time.Since(startTime)
 
-		if err != nil {
-			log.Printf("❌ LLM ERROR for %s: %v", playerName, err)
-			decision = &client.LLMDecisionResponse{
-				Action: "FOLD",
-				Amount: 0,
-				Reason: "LLM service error, auto-fold",
+		r.do(func() {
+			if finish() {
+				r.llmReady = r.llmReady || checkHealth
+				r.applyDecision(p, decision, err, startTime, apiDuration)
 			}
-		}
+		})
+	}()
+}
 
-		actionEmoji := map[string]string{
-			"FOLD": "🚫", "CHECK": "✋", "CALL": "📞",
-			"RAISE": "📈", "BET": "💰", "ALL_IN": "🔥",
-		}[decision.Action]
-		if actionEmoji == "" {
-			actionEmoji = "❓"
-		}
+// applyDecision plays a decider's answer, auto-folding on errors and illegal actions,
+// and records it for the dataset. Answers for a turn the game has moved past (after an
+// undo, rewind or new hand) are dropped.
+func (r *room) applyDecision(p pendingDecision, decision *decider.Decision, llmErr error, startTime time.Time, apiDuration time.Duration) {
+	gs := r.gs
+	if p.version != r.version {
+		log.Printf("Dropping stale decision from %s", p.playerName)
+		r.next()
+		return
+	}
 
-		log.Printf("%s %s: %s", actionEmoji, playerName, decision.Action)
-		if decision.Amount > 0 {
-			log.Printf("   Amount: ¤%d", decision.Amount)
+	if llmErr != nil {
+		log.Printf("❌ %s ERROR for %s: %v", p.decider.Kind(), p.playerName, llmErr)
+		decision = &decider.Decision{
+			Action: "FOLD",
+			Amount: 0,
+			Reason: fmt.Sprintf("%s decider error, auto-fold", p.decider.Kind()),
 		}
-		log.Printf("   Reason: %s", decision.Reason)
-		log.Printf("   ⏱️  %dms", apiDuration.Milliseconds())
-		log.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	}
+
+	actionEmoji := map[string]string{
+		"FOLD": "🚫", "CHECK": "✋", "CALL": "📞",
+		"RAISE": "📈", "BET": "💰", "ALL_IN": "🔥",
+	}[decision.Action]
+	if actionEmoji == "" {
+		actionEmoji = "❓"
+	}
 
-		// Send complete decision to frontend (action + reasoning together)
-		// Frontend will handle timing for display
-		s.send(conn, ServerMessage{
+	log.Printf("%s %s: %s", actionEmoji, p.playerName, decision.Action)
+	if decision.Amount > 0 {
+		log.Printf("   Amount: ¤%d", decision.Amount)
+	}
+	log.Printf("   Reason: %s", decision.Reason)
+	log.Printf("   ⏱️  %dms | 📊 ~%d prompt tokens", apiDuration.Milliseconds(), p.payload.EstimatedTokens)
+	log.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	// Send complete decision to frontend (action + reasoning together)
+	// Frontend will handle timing for display
+	r.publish(func(vis Visibility) ServerMessage {
+		reason := decision.Reason
+		if !vis.Reasons && !vis.canSee(gs, p.playerIdx) {
+			reason = ""
+		}
+		return ServerMessage{
 			Type: MsgLLMAction,
 			Payload: LLMActionPayload{
-				PlayerIdx:  playerIdx,
-				PlayerName: playerName,
-				Action:     decision.Action,
-				Amount:     decision.Amount,
-				Reason:     decision.Reason,
+				PlayerIdx:    p.playerIdx,
+				PlayerName:   p.playerName,
+				Action:       decision.Action,
+				Amount:       decision.Amount,
+				Reason:       reason,
+				PromptTokens: p.payload.EstimatedTokens,
 			},
-		})
-
-		// Process action immediately (compute ahead)
-		action := game.Action{
-			Type:      ParseActionType(decision.Action),
-			Amount:    decision.Amount,
-			PlayerIdx: playerIdx,
-		}
-
-		if err := gs.ProcessAction(action); err != nil {
-			log.Printf("Error processing LLM action: %v", err)
-			action.Type = game.ActionFold
-			action.Amount = 0
-			gs.ProcessAction(action)
 		}
+	})
 
-		if gs.NeedToAdvanceStreet() {
-			if err := gs.AdvanceStreet(); err != nil {
-				log.Printf("Error advancing street: %v", err)
-			}
+	record := dataset.Record{
+		GameID:       gs.ID,
+		HandNumber:   gs.HandNumber,
+		Source:       recordSource(p.decider),
+		Model:        p.playerName,
+		Seat:         p.playerIdx,
+		Street:       p.street,
+		Time:         startTime,
+		Prompt:       p.payload,
+		PromptTokens: p.payload.EstimatedTokens,
+		Response: dataset.Response{
+			Action:    decision.Action,
+			Amount:    decision.Amount,
+			Reason:    decision.Reason,
+			Raw:       decision.Raw,
+			LatencyMs: decision.LatencyMs,
+		},
+		Legal: true,
+		Seq:   len(gs.Events), // The action is the next event
+	}
 
-			s.send(conn, ServerMessage{
-				Type: MsgStreetChange,
-				Payload: map[string]interface{}{
-					"street": gs.Street.String(),
-				},
-			})
-		}
+	action := game.Action{
+		Type:      ParseActionType(decision.Action),
+		Amount:    decision.Amount,
+		PlayerIdx: p.playerIdx,
+	}
 
-		if gs.IsHandComplete() {
-			gs.EliminateBrokePlayers()
+	if err := gs.ProcessAction(action); err != nil {
+		log.Printf("Error processing LLM action: %v", err)
+		record.Legal = false
+		record.Error = err.Error()
+		action.Type = game.ActionFold
+		action.Amount = 0
+		gs.ProcessAction(action)
+	}
+	r.version++
 
-			s.send(conn, ServerMessage{
-				Type: MsgHandComplete,
-				Payload: HandCompletePayload{
-					Winners:    convertWinners(gs.Winners),
-					HandNumber: gs.HandNumber,
-				},
-			})
+	// Service errors were auto-folds, not model decisions, so keep them out of the dataset
+	if llmErr == nil {
+		if last := gs.Players[p.playerIdx].LastAction; last != nil {
+			record.ExecutedAction = last.Type.String()
+			record.ExecutedAmount = last.Amount
 		}
+		r.s.decisions.Add(record)
+	}
 
-		// Send updated game state
-		s.sendGameState(conn, gs)
-
-		// Pending pause is checked at the start of the loop before next LLM query
+	// No delay here - settle asks the next player immediately
+	// Frontend handles all display timing
+	r.settle()
+}
 
-		// No delay here - continue to next player immediately
-		// Frontend handles all display timing
+func recordSource(d decider.Decider) string {
+	if d.Kind() == decider.KindBot {
+		return dataset.SourceBot
 	}
+	return dataset.SourceLLM
+}
+
+// completeHandForDataset queues the finished hand's decisions, with their outcomes, to
+// be graded and published.
+func (s *Server) completeHandForDataset(gs *game.GameState) {
+	s.decisions.CompleteHand(gs)
 }
 
 // buildLLMValidActions converts game valid actions to LLM-friendly format
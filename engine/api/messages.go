@@ -16,6 +16,12 @@ const (
 	MsgGetState  MessageType = "get_state"
 	MsgPause     MessageType = "pause"
 	MsgResume    MessageType = "resume"
+	MsgUndo      MessageType = "undo"        // Test mode only
+	MsgRewind    MessageType = "rewind"      // Test mode only
+	MsgJoin      MessageType = "join"        // Spectate a game by ID
+	MsgRejoin    MessageType = "rejoin"      // Take back a game with a session token
+	MsgResync    MessageType = "resync_from" // Replay broadcasts missed since a seq
+	MsgEndGame   MessageType = "end_game"    // Finish the game now
 
 	// Server → Client
 	MsgGameState      MessageType = "game_state"
@@ -30,6 +36,13 @@ const (
 	MsgResumed        MessageType = "resumed"
 	MsgButtonCard     MessageType = "button_card"     // Card dealt for button determination
 	MsgButtonWinner   MessageType = "button_winner"   // Who won the button
+	MsgStatsUpdate    MessageType = "stats_update"    // Per-seat HUD stats after each hand
+	MsgJoined         MessageType = "joined"          // Reply to join, followed by game_state
+	MsgSession        MessageType = "session"         // Session tokens for rejoin, after new_game and rejoin
+	MsgMissedEvents   MessageType = "missed_events"   // Reply to rejoin, followed by game_state
+	MsgAutoAction     MessageType = "auto_action"     // A disconnected or timed out seat was checked or folded
+	MsgClockTick      MessageType = "clock_tick"      // Time left for the seat to act, every second
+	MsgGameOver       MessageType = "game_over"       // The game finished or was abandoned, with its results
 )
 
 type ClientMessage struct {
@@ -40,21 +53,91 @@ type ClientMessage struct {
 type ServerMessage struct {
 	Type    MessageType `json:"type"`
 	Payload interface{} `json:"payload,omitempty"`
+	Seq     int         `json:"seq,omitempty"` // Per game, on broadcasts; see resync.go
 }
 
 type NewGamePayload struct {
-	PlayerNames   []string `json:"playerNames"`
-	StartingStack int      `json:"startingStack"`
-	SmallBlind    int      `json:"smallBlind"`
-	BigBlind      int      `json:"bigBlind"`
-	Mode          string   `json:"mode"` // "simulate", "play", "test"
-	UserSeatIdx   int      `json:"userSeatIdx"`
+	PlayerNames   []string          `json:"playerNames"`
+	StartingStack int               `json:"startingStack"`
+	SmallBlind    int               `json:"smallBlind"`
+	BigBlind      int               `json:"bigBlind"`
+	Mode          string            `json:"mode"` // "simulate", "play", "test"
+	UserSeatIdx   int               `json:"userSeatIdx"`
+	UserName      string            `json:"userName,omitempty"` // Tags the user's decisions for SFT export
+	Prompt        game.PromptConfig `json:"prompt"`             // Optional LLM prompt sections (HUD, ...)
+	Bots          map[int]string    `json:"bots,omitempty"`     // Seat -> "random", "station", "tag" or "pushfold"; overrides the mode's decider
+
+	SpectatorDelayMs int `json:"spectatorDelayMs,omitempty"` // Spectators see every card this late; 0 shows them only shown-down hands
+
+	ShotClockMs        int `json:"shotClockMs,omitempty"`        // Time each seat gets to act; 0 for no clock
+	TimeBankMs         int `json:"timeBankMs,omitempty"`         // Extra time per seat, refilled every level
+	TimeBankLevelHands int `json:"timeBankLevelHands,omitempty"` // Hands per level (default 10)
 }
 
 type ActionPayload struct {
 	PlayerIdx int    `json:"playerIdx"`
 	Action    string `json:"action"`
 	Amount    int    `json:"amount,omitempty"`
+
+	// Guards against duplicated and late clicks. ActionID and HandNumber are checked when
+	// set; TurnToken is required.
+	ActionID   string `json:"actionId,omitempty"`   // Client-chosen, one per turn; a resend with the same ID is rejected as a duplicate
+	HandNumber int    `json:"handNumber,omitempty"` // Hand the action is for
+	TurnToken  int    `json:"turnToken"`            // From action_required
+}
+
+type PausePayload struct {
+	Hard bool `json:"hard,omitempty"` // Cancel the decision in flight; the seat is asked again on resume
+}
+
+type JoinPayload struct {
+	GameID string `json:"gameId"`
+	Token  string `json:"token,omitempty"` // ADMIN_TOKEN to see every card instantly
+}
+
+type JoinedPayload struct {
+	GameID     string `json:"gameId"`
+	Spectators int    `json:"spectators"` // Including the one that just joined
+	Admin      bool   `json:"admin"`
+	DelayMs    int    `json:"delayMs"` // Spectator delay, see NewGamePayload
+}
+
+// RejoinPayload.LastEvent is a game.Event seq from the event log, not a ServerMessage
+// seq. Undo and rewind rewrite the log from the point they return to, so after either a
+// client should send -1 and replay the whole game.
+type RejoinPayload struct {
+	GameID    string `json:"gameId"`
+	Token     string `json:"token"`     // From the session message
+	LastEvent int    `json:"lastEvent"` // Seq of the last event seen; -1 replays the whole game
+}
+
+type SessionPayload struct {
+	GameID string         `json:"gameId"`
+	Tokens map[int]string `json:"tokens"` // Seat -> token; seat -1 in simulate mode
+}
+
+type MissedEventsPayload struct {
+	Events []game.Event `json:"events"` // Hole cards and deck order hidden per visibility.go
+}
+
+type AutoActionPayload struct {
+	PlayerIdx int    `json:"playerIdx"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"` // "disconnected" or "timeout"
+}
+
+type ClockTickPayload struct {
+	PlayerIdx   int `json:"playerIdx"`
+	RemainingMs int `json:"remainingMs"` // Shot clock left
+	TimeBankMs  int `json:"timeBankMs"`  // Bank left, used once the shot clock runs out
+}
+
+type ResyncPayload struct {
+	From int `json:"from"` // Last seq received
+}
+
+type RewindPayload struct {
+	Seq int `json:"seq"` // Event to rewind to (see GET /api/games/{id}/events)
 }
 
 type GameStatePayload struct {
@@ -69,6 +152,7 @@ type GameStatePayload struct {
 	ButtonIdx        int                  `json:"buttonIdx"`
 	Players          []PlayerStatePayload `json:"players"`
 	ValidActions     []ValidActionPayload `json:"validActions,omitempty"`
+	TurnToken        int                  `json:"turnToken,omitempty"` // While a seat is to act; echo in ActionPayload
 	Winners          []WinnerPayload      `json:"winners,omitempty"`
 	Mode             string               `json:"mode"`
 	Stakes           StakesPayload        `json:"stakes"`
@@ -108,8 +192,21 @@ type StakesPayload struct {
 	BigBlind   int `json:"bigBlind"`
 }
 
+type StatsPayload struct {
+	GameID     string             `json:"gameId"`
+	HandNumber int                `json:"handNumber"`
+	Players    []game.PlayerStats `json:"players"` // Indexed by seat
+}
+
+// Error codes for errors a client may want to handle rather than show
+const (
+	ErrStaleAction     = "stale_action"     // The action's hand or turn is over
+	ErrDuplicateAction = "duplicate_action" // An action with this ID was already applied
+)
+
 type ErrorPayload struct {
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"` // ErrStaleAction, ...
 }
 
 type ActionRequiredPayload struct {
@@ -117,6 +214,8 @@ type ActionRequiredPayload struct {
 	PlayerName   string               `json:"playerName"`
 	ValidActions []ValidActionPayload `json:"validActions"`
 	TimeoutMs    int                  `json:"timeoutMs,omitempty"`
+	HandNumber   int                  `json:"handNumber"`
+	TurnToken    int                  `json:"turnToken"` // Echo in ActionPayload; changes whenever the game does
 }
 
 type HandCompletePayload struct {
@@ -131,11 +230,47 @@ type LLMThinkingPayload struct {
 }
 
 type LLMActionPayload struct {
-	PlayerIdx  int    `json:"playerIdx"`
-	PlayerName string `json:"playerName"`
-	Action     string `json:"action"`
-	Amount     int    `json:"amount"`
-	Reason     string `json:"reason"`
+	PlayerIdx    int    `json:"playerIdx"`
+	PlayerName   string `json:"playerName"`
+	Action       string `json:"action"`
+	Amount       int    `json:"amount"`
+	Reason       string `json:"reason"`
+	PromptTokens int    `json:"promptTokens,omitempty"` // Estimated size of the prompt sent
+}
+
+type GameResultPayload struct {
+	GameID    string                `json:"gameId"`
+	State     string                `json:"state"`  // "finished" or "abandoned"
+	Reason    string                `json:"reason"` // "won", "ended" (end_game) or "idle"
+	Mode      string                `json:"mode"`
+	Hands     int                   `json:"hands"`
+	StartedAt string                `json:"startedAt"`
+	EndedAt   string                `json:"endedAt"`
+	Players   []PlayerResultPayload `json:"players"` // Indexed by seat
+}
+
+type PlayerResultPayload struct {
+	Seat  int    `json:"seat"`
+	Name  string `json:"name"`
+	Stack int    `json:"stack"`
+	Net   int    `json:"net"` // Stack minus the stack the player started the game with
+}
+
+// HandSummaryPayload is one entry of GET /api/games/{id}/hands.
+type HandSummaryPayload struct {
+	Number   int             `json:"number"`
+	Button   int             `json:"button"`
+	Stacks   []int           `json:"stacks"` // Before blinds, indexed by seat
+	Complete bool            `json:"complete"`
+	Board    []string        `json:"board"`
+	Awards   []WinnerPayload `json:"awards,omitempty"` // Pots won, once complete
+}
+
+// HandHistoryPayload is GET /api/games/{id}/hands/{n}.
+type HandHistoryPayload struct {
+	HandSummaryPayload
+	Events     []game.Event `json:"events"`               // Hole cards and deck hidden per visibility.go
+	PokerStars string       `json:"pokerStars,omitempty"` // PokerStars hand history once complete, for viewers who see every card or the hero
 }
 
 type ButtonCardPayload struct {
@@ -149,17 +284,12 @@ type ButtonWinnerPayload struct {
 	PlayerName string `json:"playerName"`
 }
 
-func ConvertGameState(gs *game.GameState, showAllCards bool) GameStatePayload {
+// ConvertGameState renders gs with only the hole cards vis may see.
+func ConvertGameState(gs *game.GameState, vis Visibility) GameStatePayload {
 	players := make([]PlayerStatePayload, len(gs.Players))
 	for i, p := range gs.Players {
 		var holeCards []string
-		canSeeCards := showAllCards ||
-			gs.Mode == game.ModeSimulate ||
-			gs.Street == game.StreetShowdown ||
-			gs.Street == game.StreetComplete ||
-			(gs.Mode == game.ModePlay && i == gs.UserSeatIdx)
-
-		if canSeeCards {
+		if vis.canSee(gs, i) {
 			for _, c := range p.HoleCards {
 				holeCards = append(holeCards, c.String())
 			}
@@ -242,9 +372,9 @@ func ParseActionType(s string) game.ActionType {
 		return game.ActionCheck
 	case "CALL", "call":
 		return game.ActionCall
-	case "RAISE", "raise":
+	case "RAISE", "raise", "BET", "bet":
 		return game.ActionRaise
-	case "ALL-IN", "all-in", "allin":
+	case "ALL-IN", "all-in", "allin", "ALL_IN", "all_in":
 		return game.ActionAllIn
 	default:
 		return game.ActionFold
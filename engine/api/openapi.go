@@ -0,0 +1,230 @@
+// OpenAPI 3 spec for the HTTP endpoints in rest.go, served at /api/openapi.json. Schemas
+// are generated from the payload types in messages.go (and the game types they embed) by
+// reflection, so they can't drift from what the handlers encode. Only the routes table
+// below is written by hand.
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type apiParam struct {
+	name, in, description string
+	integer               bool
+}
+
+type apiRoute struct {
+	method, path, summary string
+	auth                  bool // Needs a session token
+	params                []apiParam
+	request               any // Zero value of the request body type, nil for none
+	response              any // Zero value of the response body type
+	status                int // Success status
+}
+
+var (
+	gameIDParam = apiParam{name: "id", in: "path", description: "Game ID"}
+	tokenParam  = apiParam{name: "token", in: "query", description: "Session token or ADMIN_TOKEN, instead of the Authorization header"}
+	viewerNote  = "Hole cards and LLM reasons are hidden per the caller's token: a session token sees what the game's owner sees, ADMIN_TOKEN every card, anyone else what spectators see."
+)
+
+var apiRoutes = []apiRoute{
+	{
+		method: "get", path: "/api/games", summary: "List games",
+		params:   []apiParam{{name: "state", in: "query", description: "Comma-separated states to keep: lobby, running, paused, finished, abandoned"}},
+		response: []map[string]any{}, status: http.StatusOK,
+	},
+	{
+		method: "post", path: "/api/games", summary: "Create a game and get its session tokens",
+		request: NewGamePayload{}, response: SessionPayload{}, status: http.StatusCreated,
+	},
+	{
+		method: "post", path: "/api/games/{id}/hands", summary: "Start the next hand",
+		auth: true, params: []apiParam{gameIDParam, tokenParam},
+		response: GameStatePayload{}, status: http.StatusOK,
+	},
+	{
+		method: "post", path: "/api/games/{id}/actions", summary: "Act for a human seat. turnToken comes from the state while the seat is to act; an action without it, or for a turn that is over, is rejected",
+		auth: true, params: []apiParam{gameIDParam, tokenParam},
+		request: ActionPayload{}, response: GameStatePayload{}, status: http.StatusOK,
+	},
+	{
+		method: "get", path: "/api/games/{id}/state", summary: "Get the game state. " + viewerNote,
+		params:   []apiParam{gameIDParam, tokenParam},
+		response: GameStatePayload{}, status: http.StatusOK,
+	},
+	{
+		method: "get", path: "/api/games/{id}/hands", summary: "List the game's hands",
+		params:   []apiParam{gameIDParam},
+		response: []HandSummaryPayload{}, status: http.StatusOK,
+	},
+	{
+		method: "get", path: "/api/games/{id}/hands/{n}", summary: "Get one hand's history. Outside test mode the hand in progress is only served to ADMIN_TOKEN. " + viewerNote,
+		params:   []apiParam{gameIDParam, {name: "n", in: "path", description: "Hand number", integer: true}, tokenParam},
+		response: HandHistoryPayload{}, status: http.StatusOK,
+	},
+}
+
+var openAPISpec = sync.OnceValue(func() map[string]any {
+	g := &schemaGen{schemas: make(map[string]any)}
+	errorSchema := g.schema(reflect.TypeOf(ErrorPayload{}))
+
+	paths := make(map[string]any)
+	for _, route := range apiRoutes {
+		op := map[string]any{
+			"summary": route.summary,
+			"responses": map[string]any{
+				strconv.Itoa(route.status): map[string]any{
+					"description": "OK",
+					"content":     jsonContent(g.schema(reflect.TypeOf(route.response))),
+				},
+				"default": map[string]any{
+					"description": "Error",
+					"content":     jsonContent(errorSchema),
+				},
+			},
+		}
+		var params []any
+		for _, p := range route.params {
+			typ := "string"
+			if p.integer {
+				typ = "integer"
+			}
+			params = append(params, map[string]any{
+				"name":        p.name,
+				"in":          p.in,
+				"required":    p.in == "path",
+				"description": p.description,
+				"schema":      map[string]any{"type": typ},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if route.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(g.schema(reflect.TypeOf(route.request))),
+			}
+		}
+		if route.auth {
+			op["security"] = []any{map[string]any{"sessionToken": []any{}}}
+		}
+
+		if paths[route.path] == nil {
+			paths[route.path] = make(map[string]any)
+		}
+		paths[route.path].(map[string]any)[route.method] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "No-LLMit engine",
+			"version": "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]any{
+				"sessionToken": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+})
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, openAPISpec())
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaGen turns Go types into JSON schemas, collecting named structs as components.
+type schemaGen struct {
+	schemas map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			g.schemas[t.Name()] = nil // Placeholder, so recursive types terminate
+			g.schemas[t.Name()] = g.object(t)
+		}
+		return ref
+	default:
+		// interface{} payloads can be anything
+		return map[string]any{}
+	}
+}
+
+// object is the schema of struct t's JSON fields. Fields without omitempty are required.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	var embedded []any
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded = append(embedded, g.schema(f.Type))
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	obj := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		obj["required"] = required
+	}
+	if embedded != nil {
+		return map[string]any{"allOf": append(embedded, obj)}
+	}
+	return obj
+}
@@ -0,0 +1,245 @@
+// HTTP endpoints to drive a table without a WebSocket, for scripts and notebooks:
+//
+//	POST /api/games                    create a game (NewGamePayload), returns its session tokens
+//	POST /api/games/{id}/hands         start the next hand
+//	POST /api/games/{id}/actions       act for a human seat (ActionPayload, with the state's turnToken)
+//	GET  /api/games/{id}/state         the game as the caller may see it
+//	GET  /api/games/{id}/hands         every hand so far
+//	GET  /api/games/{id}/hands/{n}     one hand's history
+//
+// Control endpoints need one of the game's session tokens (session.go) as
+// "Authorization: Bearer <token>" or ?token=. Reads follow visibility.go: a session token
+// sees what the game's owner sees, ADMIN_TOKEN every card, anyone else what spectators
+// see. Bots and LLMs act in the background, so poll state to follow the game. The spec
+// is served at /api/openapi.json (openapi.go).
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/history"
+)
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	payload := ErrorPayload{Message: err.Error()}
+	var ce *codedError
+	if errors.As(err, &ce) {
+		payload.Code = ce.code
+	}
+	writeJSON(w, status, payload)
+}
+
+// requestToken is the token a request carries, from its Authorization header or ?token=.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("token")
+}
+
+// handleGames lists games (GET) or creates one (POST).
+func (s *Server) handleGames(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.handleCreateGame(w, r)
+		return
+	}
+	s.handleListGames(w, r)
+}
+
+func (s *Server) handleCreateGame(w http.ResponseWriter, r *http.Request) {
+	var ngp NewGamePayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&ngp); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("Invalid new game payload"))
+		return
+	}
+
+	rm, err := s.createGame(&ngp, nil)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	s.games[rm.id] = rm
+	s.mu.Unlock()
+
+	rm.do(func() { rm.overHTTP = true })
+	rm.do(rm.determineButton)
+
+	writeJSON(w, http.StatusCreated, rm.sessionPayload())
+}
+
+// control runs f on rm's room for a request holding one of its session tokens and
+// replies with the game as the owner sees it.
+func (s *Server) control(w http.ResponseWriter, r *http.Request, rm *room, f func() error) {
+	if _, ok := rm.sessions[requestToken(r)]; !ok {
+		writeError(w, http.StatusUnauthorized, errors.New("A session token for this game is required"))
+		return
+	}
+
+	var err error
+	var msg ServerMessage
+	rm.query(func() {
+		if rm.result != nil {
+			err = errors.New("Game is over")
+			return
+		}
+		rm.overHTTP = true
+		if err = f(); err == nil {
+			msg = rm.stateMessage(rm.ownerVisibility())
+		}
+	})
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, msg.Payload)
+}
+
+// handleHands lists a game's hands (GET) or starts the next one (POST).
+func (s *Server) handleHands(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+	if r.Method == http.MethodPost {
+		s.control(w, r, rm, rm.startHand)
+		return
+	}
+
+	var hands []HandSummaryPayload
+	rm.query(func() {
+		last := rm.lastHand(s.view(r, rm))
+		for _, h := range rm.gs.Hands() {
+			if h.Number <= last {
+				hands = append(hands, handSummary(h))
+			}
+		}
+	})
+	writeJSON(w, http.StatusOK, hands)
+}
+
+func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST an action", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var ap ActionPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&ap); err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("Invalid action payload"))
+		return
+	}
+	s.control(w, r, rm, func() error { return rm.act(&ap) })
+}
+
+// view is the visibility a request gets, see the top of this file.
+func (s *Server) view(r *http.Request, rm *room) (vis Visibility, spectator bool) {
+	token := requestToken(r)
+	switch _, owner := rm.sessions[token]; {
+	case s.isAdmin(token):
+		return adminVisibility, false
+	case owner:
+		return rm.ownerVisibility(), false
+	default:
+		return spectatorVisibility, true
+	}
+}
+
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+
+	var msg ServerMessage
+	rm.query(func() {
+		vis, spectator := s.view(r, rm)
+		if spectator {
+			msg = rm.spectatorState(false)
+			return
+		}
+		msg = rm.stateMessage(vis)
+	})
+	writeJSON(w, http.StatusOK, msg.Payload)
+}
+
+func (s *Server) handleHand(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "hand number must be an integer", http.StatusBadRequest)
+		return
+	}
+
+	var hand *HandHistoryPayload
+	rm.query(func() {
+		vis, spectator := s.view(r, rm)
+		if n > rm.lastHand(vis, spectator) {
+			return // In progress, or not through the spectator delay yet
+		}
+		for _, h := range rm.gs.Hands() {
+			if h.Number != n {
+				continue
+			}
+			hand = &HandHistoryPayload{
+				HandSummaryPayload: handSummary(h),
+				Events:             redactEvents(rm.gs, h.Events, vis),
+			}
+			// The text shows what an all-seeing observer, or the hero in play mode, saw
+			if h.Complete() && (vis.All || rm.gs.Mode == game.ModePlay && vis.Seat == rm.gs.UserSeatIdx) {
+				hand.PokerStars = history.FormatPokerStars(rm.gs, h, exportSeat(vis))
+			}
+		}
+	})
+	if hand == nil {
+		http.Error(w, "hand not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, hand)
+}
+
+func handSummary(h game.HandRecord) HandSummaryPayload {
+	board := []string{}
+	for _, c := range h.Board() {
+		board = append(board, c.String())
+	}
+
+	var awards []WinnerPayload
+	for _, ev := range h.Events {
+		if ev.Type == game.EventAward {
+			awards = append(awards, WinnerPayload{
+				PlayerIdx: ev.PlayerIdx,
+				Amount:    ev.Amount,
+				HandDesc:  ev.HandDesc,
+				PotNumber: ev.PotNumber,
+			})
+		}
+	}
+
+	return HandSummaryPayload{
+		Number:   h.Number,
+		Button:   h.Button,
+		Stacks:   h.Stacks,
+		Complete: h.Complete(),
+		Board:    board,
+		Awards:   awards,
+	}
+}
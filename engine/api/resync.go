@@ -0,0 +1,84 @@
+// Every broadcast a room sends carries the game's next sequence number (ServerMessage.Seq),
+// so a client that sees a gap knows it missed something. It asks for the rest with
+// resync_from: the room keeps the last resyncBuffer broadcasts, rendered for each kind of
+// viewer, and replays the ones after the client's seq. When those are no longer buffered
+// the client gets a fresh game_state instead, stamped with the latest seq.
+//
+// Replies to a single client (errors, joined, session, ...) carry no seq; game_state
+// replies to get_state, join and rejoin carry the seq they are current as of.
+package api
+
+import "log"
+
+const resyncBuffer = 256
+
+// sentMessage is one broadcast as each kind of viewer got it, see room.publish.
+type sentMessage struct {
+	owner ServerMessage // Rendered for the owner
+	all   ServerMessage // Every card: admins and, after the delay, other spectators
+	live  ServerMessage // Spectators without a delay
+}
+
+// record stamps a broadcast with the next seq and keeps it for resync.
+func (r *room) record(owner, all, live ServerMessage) sentMessage {
+	r.seq++
+	owner.Seq, all.Seq, live.Seq = r.seq, r.seq, r.seq
+	m := sentMessage{owner: owner, all: all, live: live}
+
+	r.sent = append(r.sent, m)
+	if len(r.sent) > resyncBuffer {
+		r.sent = r.sent[len(r.sent)-resyncBuffer:]
+	}
+	return m
+}
+
+// stateFor is the game_state c gets on request, stamped with the seq it is current as of.
+func (r *room) stateFor(c *wsConn) ServerMessage {
+	if c == r.owner {
+		msg := r.stateMessage(r.ownerVisibility())
+		msg.Seq = r.seq
+		return msg
+	}
+	return r.spectatorState(r.spectators[c])
+}
+
+func (r *room) handleResync(c *wsConn, payload interface{}) {
+	rp, err := parsePayload[ResyncPayload](payload)
+	if err != nil {
+		c.sendError("Invalid resync payload")
+		return
+	}
+
+	// Non-admin spectators of a delayed game can only catch up to what has come
+	// through the delay
+	admin, spectator := r.spectators[c]
+	delayed := spectator && !admin && r.spectatorDelay > 0
+	latest := r.seq
+	if delayed {
+		latest = r.released
+	}
+
+	oldest := latest + 1
+	if len(r.sent) > 0 {
+		oldest = r.sent[0].owner.Seq
+	}
+	if rp.From < oldest-1 || rp.From > latest {
+		log.Printf("Game %s: resync from %d not buffered, sending state at %d", r.id, rp.From, latest)
+		c.send(r.stateFor(c))
+		return
+	}
+
+	for _, m := range r.sent[rp.From+1-oldest:] {
+		if m.owner.Seq > latest {
+			break
+		}
+		switch {
+		case c == r.owner:
+			c.send(m.owner)
+		case admin || delayed:
+			c.send(m.all)
+		default:
+			c.send(m.live)
+		}
+	}
+}
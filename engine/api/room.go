@@ -0,0 +1,309 @@
+// Each game is owned by a room: one goroutine that runs every command touching the
+// game's GameState, one at a time. WebSocket handlers (game_handlers.go), decider results
+// (llm_handlers.go) and timers never mutate the game themselves; they queue a command
+// with do. HTTP handlers read the game through query.
+package api
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type room struct {
+	id       string // gs.ID, which RewindTo keeps, so it can be read off the room goroutine
+	s        *Server
+	gs       *game.GameState
+	deciders *decider.Registry // Who decides for each seat
+	commands chan func()
+	ctx      context.Context // Done once the game is over
+	end      context.CancelFunc
+	sessions map[string]int // Session token -> seat, see session.go. Read-only after newRoom
+
+	roomOptions
+
+	// Only touched on the room goroutine
+	owner      *wsConn            // Connection that created the game, nil once it disconnects
+	spectators map[*wsConn]bool   // Connections watching with join -> is an admin
+	paused     bool               // Don't ask the next seat to act until resumed
+	deciding   context.CancelFunc // Cancels the decision in flight, nil if there is none
+	llmReady   bool               // The LLM service passed a health check this hand
+	version    int                // Bumped on every change to the game, so stale decisions are dropped; also the turn token
+	actionIDs  map[string]bool    // IDs of the actions applied this hand
+
+	delayed      []delayedMessage // Broadcasts waiting out the spectator delay, oldest first
+	delayedState *ServerMessage   // Last game_state spectators got through the delay
+	released     int              // Seq of the last broadcast through the delay
+
+	seq  int           // Seq of the last broadcast, see resync.go
+	sent []sentMessage // The last resyncBuffer broadcasts, oldest first
+
+	banks []time.Duration // Time bank left per seat, see clock.go
+	turn  *turnClock      // Clock of the seat to act, nil when not running
+
+	result    *GameResultPayload // Set once the game has ended, see lifecycle.go
+	idleSince time.Time          // When the last client left, or the last HTTP request with a session token
+	overHTTP  bool               // Created or controlled over HTTP (rest.go), so played without a client
+	closed    chan struct{}      // Closed when the room is reaped; its goroutine stops
+}
+
+// roomOptions are a game's table settings from NewGamePayload.
+type roomOptions struct {
+	spectatorDelay time.Duration // How long spectators wait to see every card, 0 for showdown only
+	shotClock      time.Duration // Time to act each turn, 0 for no clock
+	timeBank       time.Duration // Extra time per seat per level
+	levelHands     int           // Hands per level
+}
+
+// newRoom starts the goroutine that owns gs.
+func (s *Server) newRoom(gs *game.GameState, deciders *decider.Registry, owner *wsConn, opts roomOptions) *room {
+	if opts.levelHands <= 0 {
+		opts.levelHands = defaultLevelHands
+	}
+	r := &room{
+		id:          gs.ID,
+		s:           s,
+		gs:          gs,
+		deciders:    deciders,
+		commands:    make(chan func(), 64),
+		roomOptions: opts,
+		owner:       owner,
+		spectators:  make(map[*wsConn]bool),
+		actionIDs:   make(map[string]bool),
+		banks:       make([]time.Duration, len(gs.Players)),
+		idleSince:   time.Now(),
+		closed:      make(chan struct{}),
+	}
+	r.ctx, r.end = context.WithCancel(context.Background())
+	r.sessions = r.issueSessions()
+	go r.run()
+	return r
+}
+
+func (r *room) run() {
+	for {
+		select {
+		case f := <-r.commands:
+			f()
+		case <-r.closed:
+			return
+		}
+	}
+}
+
+// do queues f to run on the room goroutine. It must not be called from that goroutine,
+// which would deadlock once the queue is full. Once the room is reaped f is dropped.
+func (r *room) do(f func()) {
+	select {
+	case r.commands <- f:
+	case <-r.closed:
+	}
+}
+
+// query runs f on the room goroutine and waits for it to finish, or for the room to be
+// reaped.
+func (r *room) query(f func()) {
+	done := make(chan struct{})
+	r.do(func() {
+		f()
+		close(done)
+	})
+	select {
+	case <-done:
+	case <-r.closed:
+	}
+}
+
+// settle moves the hand along after an action: it deals the next street or finishes the
+// hand, sends the new state and asks the next seat to act.
+func (r *room) settle() {
+	gs := r.gs
+	r.stopClock()
+
+	if gs.NeedToAdvanceStreet() {
+		if err := gs.AdvanceStreet(); err != nil {
+			r.sendError(err.Error())
+			return
+		}
+
+		r.send(ServerMessage{
+			Type: MsgStreetChange,
+			Payload: map[string]interface{}{
+				"street": gs.Street.String(),
+			},
+		})
+	}
+
+	if gs.IsHandComplete() {
+		gs.EliminateBrokePlayers()
+		r.s.completeHandForDataset(gs)
+
+		r.send(ServerMessage{
+			Type: MsgHandComplete,
+			Payload: HandCompletePayload{
+				Winners:    convertWinners(gs.Winners),
+				HandNumber: gs.HandNumber,
+			},
+		})
+		r.send(ServerMessage{
+			Type:    MsgStatsUpdate,
+			Payload: statsPayload(gs),
+		})
+
+		if gs.CountPlayersWithChips() < 2 {
+			r.finish(StatusFinished, "won")
+		}
+	}
+
+	r.sendGameState()
+	r.next()
+}
+
+// next asks the current seat to act: human seats get action_required, any other decider
+// is started in the background. Nothing happens while paused, after the game is over or
+// while a decision is already running, so a seat is never asked twice. Resuming,
+// finishing a decision and every action call next again; there is no polling.
+func (r *room) next() {
+	gs := r.gs
+	if r.paused || r.deciding != nil || r.ctx.Err() != nil || gs.IsHandComplete() || !gs.IsWaitingForAction() {
+		return
+	}
+
+	// Nobody is watching: wait for a client rather than spend LLM calls
+	if !r.watched() {
+		return
+	}
+
+	r.startClock()
+
+	// Human seats answer with action messages, or are acted for if their owner is gone
+	if r.isHuman(gs.CurrentPlayerIdx) {
+		r.sendActionRequired()
+		if r.owner == nil && !r.overHTTP {
+			r.awaitDisconnected()
+		}
+		return
+	}
+	r.decide(r.deciders.Get(gs.CurrentPlayerIdx))
+}
+
+// interrupt cancels the decision in flight, if any. Its seat is asked again by the next
+// call to next.
+func (r *room) interrupt() {
+	if r.deciding != nil {
+		r.deciding()
+	}
+}
+
+func (r *room) stateMessage(vis Visibility) ServerMessage {
+	state := ConvertGameState(r.gs, vis)
+	if !r.gs.IsHandComplete() && r.gs.IsWaitingForAction() {
+		state.TurnToken = r.version
+	}
+	return ServerMessage{
+		Type:    MsgGameState,
+		Payload: state,
+	}
+}
+
+func (r *room) sendGameState() {
+	r.publish(r.stateMessage)
+}
+
+func (r *room) sendActionRequired() {
+	gs := r.gs
+	if !gs.IsWaitingForAction() {
+		return
+	}
+
+	player := gs.GetCurrentPlayer()
+	if player == nil {
+		return
+	}
+
+	validActions := gs.GetValidActions()
+	var vaPayloads []ValidActionPayload
+	for _, va := range validActions {
+		vaPayloads = append(vaPayloads, ValidActionPayload{
+			Type:      va.Type.String(),
+			MinAmount: va.MinAmount,
+			MaxAmount: va.MaxAmount,
+		})
+	}
+
+	r.send(ServerMessage{
+		Type: MsgActionReq,
+		Payload: ActionRequiredPayload{
+			PlayerIdx:    gs.CurrentPlayerIdx,
+			PlayerName:   player.Name,
+			ValidActions: vaPayloads,
+			TimeoutMs:    r.timeoutMs(),
+			HandNumber:   gs.HandNumber,
+			TurnToken:    r.version,
+		},
+	})
+}
+
+func (r *room) sendError(message string) {
+	log.Printf("Error: %s", message)
+	r.send(ServerMessage{
+		Type:    MsgError,
+		Payload: ErrorPayload{Message: message},
+	})
+}
+
+// send broadcasts msg, which reveals nothing private, to the owner and every spectator.
+func (r *room) send(msg ServerMessage) {
+	r.publish(func(Visibility) ServerMessage { return msg })
+}
+
+// publish broadcasts a message rendered by render for what each client may see, see
+// visibility.go. Spectators get it through the spectator delay when the game has one.
+// Every broadcast is numbered and kept for resync, see resync.go.
+func (r *room) publish(render func(vis Visibility) ServerMessage) {
+	m := r.record(render(r.ownerVisibility()), render(adminVisibility), render(spectatorVisibility))
+	if r.owner != nil {
+		r.owner.send(m.owner)
+	}
+
+	// The delay runs while nobody is watching too: spectators who join or read over
+	// HTTP later get what has come through it
+	if r.spectatorDelay > 0 {
+		r.delay(m.all)
+	}
+	for c, admin := range r.spectators {
+		switch {
+		case admin:
+			c.send(m.all)
+		case r.spectatorDelay == 0:
+			c.send(m.live)
+		}
+	}
+}
+
+// detach removes c from the room. When the last client is gone the decision in flight
+// is cancelled and the game waits until someone is watching again. A human seat left to
+// act by its owner is timed out, see session.go.
+func (r *room) detach(c *wsConn) {
+	if r.owner == c {
+		r.owner = nil
+		if gs := r.gs; !r.overHTTP && !r.paused && !gs.IsHandComplete() && gs.IsWaitingForAction() && r.isHuman(gs.CurrentPlayerIdx) {
+			r.awaitDisconnected()
+		}
+	}
+	delete(r.spectators, c)
+
+	if !r.watched() {
+		r.interrupt()
+		r.idleSince = time.Now()
+	}
+}
+
+// watched reports whether anyone is following the game: a connected client, or a
+// script driving it over HTTP.
+func (r *room) watched() bool {
+	return r.owner != nil || len(r.spectators) > 0 || r.overHTTP
+}
@@ -0,0 +1,335 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s := NewServer()
+	ts := httptest.NewServer(s.routes())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// testClient is a WebSocket client. gorilla/websocket allows one writer at a time, and
+// tests write from several goroutines.
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+type testMessage struct {
+	Type    MessageType     `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	Seq     int             `json:"seq"`
+}
+
+func dialTest(t *testing.T, ts *httptest.Server) *testClient {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn}
+}
+
+func (c *testClient) send(typ MessageType, payload any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.WriteJSON(ClientMessage{Type: typ, Payload: payload}); err != nil {
+		c.t.Errorf("writing %s: %v", typ, err)
+	}
+}
+
+func (c *testClient) read() testMessage {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	var msg testMessage
+	if err := c.conn.ReadJSON(&msg); err != nil {
+		c.t.Fatalf("reading: %v", err)
+	}
+	return msg
+}
+
+// readUntil reads until a message of type typ and decodes its payload into v.
+func (c *testClient) readUntil(typ MessageType, v any) {
+	c.t.Helper()
+	for {
+		msg := c.read()
+		if msg.Type != typ {
+			continue
+		}
+		if v != nil {
+			if err := json.Unmarshal(msg.Payload, v); err != nil {
+				c.t.Fatalf("decoding %s: %v", typ, err)
+			}
+		}
+		return
+	}
+}
+
+func (s *Server) testRoom(t *testing.T, id string) *room {
+	t.Helper()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r := s.games[id]
+	if r == nil {
+		t.Fatalf("no room for game %s", id)
+	}
+	return r
+}
+
+// slowDecider plays like the TAG bot after a short random delay, so its decisions come
+// back while other commands are queued on the room.
+func slowDecider(ctx context.Context, payload *game.LLMPromptPayload) (*decider.Decision, error) {
+	select {
+	case <-time.After(time.Duration(rand.Intn(5)) * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return decider.TAG{}.Decide(ctx, payload)
+}
+
+// TestRoomConcurrentClients plays hands while the owner acts over both the WebSocket and
+// HTTP, pauses and resumes, and HTTP readers poll every read endpoint, all against one
+// room. Run with -race.
+func TestRoomConcurrentClients(t *testing.T) {
+	const (
+		hands         = 15
+		startingStack = 100000
+		players       = 4
+	)
+	s, ts := newTestServer(t)
+	owner := dialTest(t, ts)
+	owner.send(MsgNewGame, NewGamePayload{
+		PlayerNames:   []string{"me", "random", "station", "slow"},
+		StartingStack: startingStack,
+		Mode:          "play",
+		Bots:          map[int]string{1: decider.BotRandom, 2: decider.BotStation, 3: decider.BotTAG},
+	})
+
+	var session SessionPayload
+	owner.readUntil(MsgSession, &session)
+	token := session.Tokens[0]
+	rm := s.testRoom(t, session.GameID)
+	rm.query(func() { rm.deciders.Set(3, decider.Func(slowDecider)) })
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	background := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					f()
+				}
+			}
+		}()
+	}
+
+	base := ts.URL + "/api/games/" + session.GameID
+	paths := []string{
+		"/api/games",
+		"/api/games/" + session.GameID + "/state",
+		"/api/games/" + session.GameID + "/state?token=" + token,
+		"/api/games/" + session.GameID + "/hands",
+		"/api/games/" + session.GameID + "/hands/1",
+		"/api/games/" + session.GameID + "/events",
+		"/api/games/" + session.GameID + "/stats",
+		"/api/games/" + session.GameID + "/history/pokerstars",
+		"/api/games/" + session.GameID + "/history/phh",
+		"/api/stats",
+	}
+	background(func() {
+		for _, path := range paths {
+			resp, err := http.Get(ts.URL + path)
+			if err != nil {
+				t.Errorf("GET %s: %v", path, err)
+				return
+			}
+			resp.Body.Close()
+		}
+	})
+	background(func() {
+		time.Sleep(2 * time.Millisecond)
+		owner.send(MsgPause, PausePayload{Hard: rand.Intn(2) == 0})
+		owner.send(MsgGetState, nil)
+		owner.send(MsgResume, nil)
+	})
+
+	owner.send(MsgStartHand, nil)
+	played := 0
+	deadline := time.After(60 * time.Second)
+	for played < hands {
+		select {
+		case <-deadline:
+			t.Fatalf("only %d of %d hands finished", played, hands)
+		default:
+		}
+
+		msg := owner.read()
+		switch msg.Type {
+		case MsgActionReq:
+			var ar ActionRequiredPayload
+			json.Unmarshal(msg.Payload, &ar)
+			ap := ActionPayload{
+				PlayerIdx:  ar.PlayerIdx,
+				Action:     "FOLD",
+				ActionID:   fmt.Sprintf("%d-%d", ar.HandNumber, ar.TurnToken),
+				HandNumber: ar.HandNumber,
+				TurnToken:  ar.TurnToken,
+			}
+			for _, va := range ar.ValidActions {
+				if va.Type == "CHECK" || va.Type == "CALL" {
+					ap.Action = va.Type
+				}
+			}
+			// The same action over both transports: at most one may apply
+			owner.send(MsgAction, ap)
+			go postAction(t, base, token, ap)
+		case MsgHandComplete:
+			played++
+			if played < hands {
+				owner.send(MsgStartHand, nil)
+			}
+		case MsgGameOver:
+			t.Fatalf("game ended after %d hands", played)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	// A finished hand keeps its pots for display after paying them out
+	var state GameStatePayload
+	var complete bool
+	rm.query(func() {
+		state = ConvertGameState(rm.gs, adminVisibility)
+		complete = rm.gs.IsHandComplete()
+	})
+	chips := 0
+	if !complete {
+		chips = state.Pot
+	}
+	for _, p := range state.Players {
+		chips += p.Stack
+	}
+	if chips != players*startingStack {
+		t.Errorf("chips on the table = %d, want %d", chips, players*startingStack)
+	}
+	if state.HandNumber < hands {
+		t.Errorf("hand number = %d, want at least %d", state.HandNumber, hands)
+	}
+
+	// Every applied action is in the log once; duplicates were refused
+	var events []game.Event
+	rm.query(func() { events = append(events, rm.gs.Events...) })
+	for i, ev := range events {
+		if ev.Seq != i {
+			t.Fatalf("event %d has seq %d", i, ev.Seq)
+		}
+	}
+}
+
+func postAction(t *testing.T, base, token string, ap ActionPayload) {
+	body, _ := json.Marshal(ap)
+	req, err := http.NewRequest(http.MethodPost, base+"/actions", bytes.NewReader(body))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return // The test server may already be closing
+	}
+	resp.Body.Close()
+}
+
+// TestRoomIgnoresStaleDecisions rewinds while a decision is in flight: the answer for
+// the old turn must be dropped, not played into the new one.
+func TestRoomIgnoresStaleDecisions(t *testing.T) {
+	s, ts := newTestServer(t)
+	owner := dialTest(t, ts)
+	owner.send(MsgNewGame, NewGamePayload{
+		PlayerNames: []string{"a", "b", "c"},
+		Mode:        "test",
+		Bots:        map[int]string{1: decider.BotStation, 2: decider.BotStation},
+	})
+	var session SessionPayload
+	owner.readUntil(MsgSession, &session)
+	rm := s.testRoom(t, session.GameID)
+
+	// The bots answer at once until seat 0 has acted, then hold their answer
+	var holding atomic.Bool
+	asked := make(chan struct{}, 16)
+	release := make(chan struct{})
+	rm.query(func() {
+		for seat := 1; seat <= 2; seat++ {
+			rm.deciders.Set(seat, decider.Func(func(ctx context.Context, p *game.LLMPromptPayload) (*decider.Decision, error) {
+				if holding.Load() {
+					asked <- struct{}{}
+					<-release
+				}
+				for _, va := range p.ValidActions {
+					if va.Type == "CHECK" {
+						return &decider.Decision{Action: "CHECK"}, nil
+					}
+				}
+				return &decider.Decision{Action: "CALL"}, nil
+			}))
+		}
+	})
+
+	owner.send(MsgStartHand, nil)
+	var ar ActionRequiredPayload
+	owner.readUntil(MsgActionReq, &ar)
+	holding.Store(true)
+	owner.send(MsgAction, ActionPayload{PlayerIdx: 0, Action: "FOLD", HandNumber: ar.HandNumber, TurnToken: ar.TurnToken})
+	<-asked
+
+	var events int
+	rm.query(func() { events = len(rm.gs.Events) })
+	// Release the held decision once the undo has been applied. It was cancelled, so its
+	// answer is dropped and seat 0 asked again.
+	owner.send(MsgUndo, nil)
+	var after int
+	for after = events; after >= events; time.Sleep(time.Millisecond) {
+		rm.query(func() { after = len(rm.gs.Events) })
+	}
+	close(release)
+	owner.readUntil(MsgActionReq, &ar)
+
+	var folded bool
+	rm.query(func() {
+		after = len(rm.gs.Events)
+		folded = rm.gs.Players[0].Status == game.PlayerFolded
+	})
+	if after >= events {
+		t.Errorf("events after undo = %d, want fewer than %d", after, events)
+	}
+	if ar.PlayerIdx != 0 || folded {
+		t.Errorf("seat 0 should be to act again after undo (seat to act %d, folded %v)", ar.PlayerIdx, folded)
+	}
+}
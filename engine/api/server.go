@@ -1,17 +1,29 @@
 // WebSocket server that receives messages from the frontend (web/).
-// Routes messages to game_handlers.go (human actions) or llm_handlers.go (LLM turns).
+// Routes messages to game_handlers.go, run on the game's room goroutine (room.go), which
+// hands non-human turns to llm_handlers.go.
 package api
 
 import (
+	"bytes"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/rizzwareengineer/no-LLMit/engine/client"
+	"github.com/rizzwareengineer/no-LLMit/engine/dataset"
 	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/history"
 )
 
 // WebSocket connections start as HTTP, then get "upgraded" to WebSocket protocol.
@@ -25,38 +37,90 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// How long Start waits for in-flight HTTP requests when shutting down
+const shutdownTimeout = 10 * time.Second
+
 type Server struct {
-	games        map[string]*game.GameState
-	clients      map[*websocket.Conn]string // conn -> gameID
-	paused       map[string]bool            // gameID -> isPaused
-	pendingPause map[string]bool            // gameID -> pause requested (takes effect after current action)
-	mu           sync.RWMutex
+	games      map[string]*room   // gameID -> the room that owns it
+	decisions  *dataset.Store     // LLM decisions for dataset export
+	results    *resultLog         // Results of ended games, see lifecycle.go
+	clients    map[*wsConn]string // conn -> gameID
+	adminToken string             // Lets spectators see every card, see visibility.go
+	mu         sync.RWMutex
 }
 
 func NewServer() *Server {
-	return &Server{
-		games:        make(map[string]*game.GameState),
-		clients:      make(map[*websocket.Conn]string),
-		paused:       make(map[string]bool),
-		pendingPause: make(map[string]bool),
+	decisions, err := dataset.NewStore(os.Getenv("DATASET_PATH"))
+	if err != nil {
+		log.Printf("Dataset file unavailable, keeping decisions in memory only: %v", err)
+		decisions, _ = dataset.NewStore("")
+	}
+
+	results, err := newResultLog(os.Getenv("RESULTS_PATH"))
+	if err != nil {
+		log.Printf("Results file unavailable, keeping results in memory only: %v", err)
+		results, _ = newResultLog("")
+	}
+
+	s := &Server{
+		games:      make(map[string]*room),
+		decisions:  decisions,
+		results:    results,
+		clients:    make(map[*wsConn]string),
+		adminToken: os.Getenv("ADMIN_TOKEN"),
 	}
+	go s.reap()
+	return s
 }
 
-func (s *Server) Start(port int) error {
-	http.HandleFunc("/ws", s.handleWebSocket)
-	http.HandleFunc("/health", s.handleHealth)
-	http.HandleFunc("/api/games", s.handleCORS(s.handleListGames))
+// Start serves until ctx is done, then stops taking requests and waits for the hands
+// already queued for grading to reach the dataset.
+func (s *Server) Start(ctx context.Context, port int) error {
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", port), Handler: s.routes()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Starting server on %s", srv.Addr)
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Server stopped, saving dataset records")
+		err = nil
+	}
+	s.decisions.Flush()
+	return err
+}
 
-	addr := fmt.Sprintf(":%d", port)
-	log.Printf("Starting server on %s", addr)
-	return http.ListenAndServe(addr, nil)
+// routes is the server's handler, kept off http.DefaultServeMux so tests can serve it.
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/api/games", s.handleCORS(s.handleGames))
+	mux.HandleFunc("/api/games/{id}/state", s.handleCORS(s.handleState))
+	mux.HandleFunc("/api/games/{id}/hands", s.handleCORS(s.handleHands))
+	mux.HandleFunc("/api/games/{id}/hands/{n}", s.handleCORS(s.handleHand))
+	mux.HandleFunc("/api/games/{id}/actions", s.handleCORS(s.handleActions))
+	mux.HandleFunc("/api/openapi.json", s.handleCORS(s.handleOpenAPI))
+	mux.HandleFunc("/api/games/{id}/events", s.handleCORS(s.handleGameEvents))
+	mux.HandleFunc("/api/games/{id}/stats", s.handleCORS(s.handleGameStats))
+	mux.HandleFunc("/api/stats", s.handleCORS(s.handleStats))
+	mux.HandleFunc("/api/games/{id}/history/pokerstars", s.handleCORS(s.handlePokerStarsExport))
+	mux.HandleFunc("/api/games/{id}/history/phh", s.handleCORS(s.handlePHHExport))
+	mux.HandleFunc("/api/history/phh/validate", s.handleCORS(s.handlePHHValidate))
+	mux.HandleFunc("/api/dataset", s.handleCORS(s.handleDatasetExport))
+	mux.HandleFunc("/api/grades", s.handleCORS(s.handleGrades))
+	return mux
 }
 
 func (s *Server) handleCORS(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -72,17 +136,47 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// handleListGames lists live games and, from their results, reaped ones.
+// ?state=running,paused keeps only games in those states (see lifecycle.go).
 func (s *Server) handleListGames(w http.ResponseWriter, r *http.Request) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	states := make(map[string]bool)
+	for _, state := range strings.Split(r.URL.Query().Get("state"), ",") {
+		if state != "" {
+			states[state] = true
+		}
+	}
+	wanted := func(state GameStatus) bool {
+		return len(states) == 0 || states[string(state)]
+	}
 
-	var games []map[string]interface{}
-	for id, gs := range s.games {
+	games := []map[string]interface{}{}
+	live := make(map[string]bool)
+	for _, rm := range s.rooms() {
+		rm.query(func() {
+			live[rm.id] = true
+			if !wanted(rm.status()) {
+				return
+			}
+			games = append(games, map[string]interface{}{
+				"id":         rm.id,
+				"state":      rm.status(),
+				"handNumber": rm.gs.HandNumber,
+				"players":    len(rm.gs.Players),
+				"street":     rm.gs.Street.String(),
+				"spectators": len(rm.spectators),
+			})
+		})
+	}
+	for _, result := range s.results.all() {
+		if live[result.GameID] || !wanted(GameStatus(result.State)) {
+			continue
+		}
 		games = append(games, map[string]interface{}{
-			"id":         id,
-			"handNumber": gs.HandNumber,
-			"players":    len(gs.Players),
-			"street":     gs.Street.String(),
+			"id":         result.GameID,
+			"state":      result.State,
+			"handNumber": result.Hands,
+			"players":    len(result.Players),
+			"result":     result,
 		})
 	}
 
@@ -90,17 +184,300 @@ func (s *Server) handleListGames(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(games)
 }
 
+// isAdmin reports whether token is the server's ADMIN_TOKEN. Without one set nobody is.
+func (s *Server) isAdmin(token string) bool {
+	return token != "" && s.adminToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// rooms returns every game's room, for handlers that read across games.
+func (s *Server) rooms() []*room {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rooms := make([]*room, 0, len(s.games))
+	for _, rm := range s.games {
+		rooms = append(rooms, rm)
+	}
+	return rooms
+}
+
+func (s *Server) gameFromPath(w http.ResponseWriter, r *http.Request) *room {
+	s.mu.RLock()
+	rm, ok := s.games[r.PathValue("id")]
+	s.mu.RUnlock()
+	if !ok {
+		http.Error(w, "game not found", http.StatusNotFound)
+		return nil
+	}
+	// A script holding a session token is still using the game, even if it only reads
+	if _, ok := rm.sessions[requestToken(r)]; ok {
+		rm.do(func() { rm.idleSince = time.Now() })
+	}
+	return rm
+}
+
+func (s *Server) handleGameEvents(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+
+	// Only admins (?token=ADMIN_TOKEN) get the events as recorded. Everyone else gets the
+	// hands lastHand allows, redacted like the hand history.
+	var events []game.Event
+	rm.query(func() {
+		gs := rm.gs
+		vis, spectator := s.view(r, rm)
+		if vis.All {
+			events = slices.Clone(gs.Events)
+			return
+		}
+		last := rm.lastHand(vis, spectator)
+		events = gs.Events
+		if last < gs.HandNumber {
+			events = slices.DeleteFunc(slices.Clone(events), func(ev game.Event) bool { return ev.HandNumber > last })
+		}
+		events = redactEvents(gs, events, vis)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+func (s *Server) handleGameStats(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+
+	var stats StatsPayload
+	rm.query(func() { stats = statsPayload(rm.gs) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// handleStats serves stats per player name (LLM names map to models) merged across every
+// game this server has hosted. ?player=name narrows it to one player.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	totals := make(map[string]*game.StatCounts)
+	for _, rm := range s.rooms() {
+		rm.query(func() {
+			for i, c := range rm.gs.StatCounts() {
+				name := rm.gs.Players[i].Name
+				if totals[name] == nil {
+					totals[name] = &game.StatCounts{}
+				}
+				totals[name].Add(c)
+			}
+		})
+	}
+
+	names := make([]string, 0, len(totals))
+	for name := range totals {
+		if player := r.URL.Query().Get("player"); player == "" || player == name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	stats := make([]game.PlayerStats, len(names))
+	for i, name := range names {
+		stats[i] = game.NewPlayerStats(name, *totals[name])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// exportHands is what a request may download of a game's history: the completed hands,
+// less any the spectator delay still holds back, and the seat whose hole cards the
+// export may show (see visibility.go).
+func (s *Server) exportHands(r *http.Request, rm *room) ([]game.HandRecord, int) {
+	vis, spectator := s.view(r, rm)
+	hands := rm.gs.CompletedHands()
+	if spectator {
+		last := rm.spectatorHand()
+		for i, h := range hands {
+			if h.Number > last {
+				hands = hands[:i]
+				break
+			}
+		}
+	}
+	return hands, exportSeat(vis)
+}
+
+func exportSeat(vis Visibility) int {
+	switch {
+	case vis.All:
+		return history.AllSeats
+	case vis.Seat >= 0:
+		return vis.Seat
+	default:
+		return history.NoSeat
+	}
+}
+
+// handlePokerStarsExport serves every completed hand of a game the caller may see as a
+// PokerStars hand history file, ready to import into PokerTracker/HM3 or a replayer.
+func (s *Server) handlePokerStarsExport(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+
+	// Render on the room goroutine, write to the network off it
+	var buf bytes.Buffer
+	var err error
+	rm.query(func() {
+		hands, seat := s.exportHands(r, rm)
+		err = history.WritePokerStars(&buf, rm.gs, hands, seat)
+	})
+	if err != nil {
+		log.Printf("Error exporting hand history for %s: %v", rm.id, err)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", rm.id+"_pokerstars.txt"))
+	buf.WriteTo(w)
+}
+
+// handlePHHExport serves every completed hand of a game the caller may see as a PHH
+// collection (.phhs).
+func (s *Server) handlePHHExport(w http.ResponseWriter, r *http.Request) {
+	rm := s.gameFromPath(w, r)
+	if rm == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	var err error
+	rm.query(func() {
+		hands, seat := s.exportHands(r, rm)
+		err = history.WritePHH(&buf, rm.gs, hands, seat)
+	})
+	if err != nil {
+		log.Printf("Error exporting PHH for %s: %v", rm.id, err)
+	}
+
+	w.Header().Set("Content-Type", "application/toml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", rm.id+".phhs"))
+	buf.WriteTo(w)
+}
+
+// handlePHHValidate replays an uploaded .phh or .phhs body through the engine and
+// reports the resulting stacks, or the first hand that does not replay cleanly.
+func (s *Server) handlePHHValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST a .phh or .phhs file as the request body", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hands, err := history.ReadPHH(http.MaxBytesReader(w, r.Body, 10<<20))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{"valid": false, "error": err.Error()})
+		return
+	}
+
+	var results []map[string]interface{}
+	for _, h := range hands {
+		stacks := make(map[string]int, len(h.State.Players))
+		for _, p := range h.State.Players {
+			stacks[p.Name] = p.Stack
+		}
+		results = append(results, map[string]interface{}{
+			"name":            h.Name,
+			"complete":        h.State.IsHandComplete(),
+			"finishingStacks": stacks,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"valid": true, "hands": results})
+}
+
+// handleDatasetExport serves recorded decisions as JSONL (default), Parquet, or SFT chat
+// examples. Query parameters: format=jsonl|parquet|sft, source=llm|human, user, model,
+// street, from, to (YYYY-MM-DD or RFC 3339).
+func (s *Server) handleDatasetExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, err := dataset.ParseTime(q.Get("from"), false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := dataset.ParseTime(q.Get("to"), true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records := s.decisions.Records(dataset.Filter{
+		Source: q.Get("source"),
+		User:   q.Get("user"),
+		Model:  q.Get("model"),
+		Street: q.Get("street"),
+		From:   from,
+		To:     to,
+	})
+
+	switch q.Get("format") {
+	case "", "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="decisions.jsonl"`)
+		err = dataset.WriteJSONL(w, records)
+	case "parquet":
+		w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+		w.Header().Set("Content-Disposition", `attachment; filename="decisions.parquet"`)
+		err = dataset.WriteParquet(w, records)
+	case "sft":
+		systemPrompt, promptErr := client.GetSystemPrompt(r.Context())
+		if promptErr != nil {
+			http.Error(w, fmt.Sprintf("Failed to get the system prompt: %v", promptErr), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="sft.jsonl"`)
+		_, err = dataset.WriteSFT(w, records, systemPrompt)
+	default:
+		http.Error(w, "format must be jsonl, parquet or sft", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error exporting dataset: %v", err)
+	}
+}
+
+// handleGrades serves decision grades and blunder rates per model. LLM decisions are
+// summarized unless ?source= asks for human or bot ones; ?model= narrows it to one model.
+func (s *Server) handleGrades(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	source := q.Get("source")
+	if source == "" {
+		source = dataset.SourceLLM
+	}
+	records := s.decisions.Records(dataset.Filter{
+		Source: source,
+		Model:  q.Get("model"),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dataset.SummarizeGrades(records))
+}
+
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
+	c := newWSConn(conn)
 	defer func() {
-		s.mu.Lock()
-		delete(s.clients, conn)
-		s.mu.Unlock()
-		conn.Close()
+		s.leave(c)
+		c.close()
 	}()
 
 	log.Printf("New WebSocket connection from %s", conn.RemoteAddr())
@@ -125,14 +502,14 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 
 		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-		s.handleMessage(conn, message)
+		s.handleMessage(c, message)
 	}
 }
 
-func (s *Server) handleMessage(conn *websocket.Conn, message []byte) {
+func (s *Server) handleMessage(c *wsConn, message []byte) {
 	var msg ClientMessage
 	if err := json.Unmarshal(message, &msg); err != nil {
-		s.sendError(conn, "Invalid message format")
+		c.sendError("Invalid message format")
 		return
 	}
 
@@ -140,123 +517,96 @@ func (s *Server) handleMessage(conn *websocket.Conn, message []byte) {
 
 	switch msg.Type {
 	case MsgNewGame:
-		s.handleNewGame(conn, msg.Payload)
+		s.handleNewGame(c, msg.Payload)
+	case MsgJoin:
+		s.handleJoin(c, msg.Payload)
+	case MsgRejoin:
+		s.handleRejoin(c, msg.Payload)
 	case MsgStartHand:
-		s.handleStartHand(conn)
+		s.inRoom(c, true, func(r *room) { r.handleStartHand(c) })
 	case MsgAction:
-		s.handleAction(conn, msg.Payload)
+		s.inRoom(c, true, func(r *room) { r.handleAction(c, msg.Payload) })
 	case MsgGetState:
-		s.handleGetState(conn)
+		s.inRoom(c, false, func(r *room) { r.handleGetState(c) })
+	case MsgResync:
+		s.inRoom(c, false, func(r *room) { r.handleResync(c, msg.Payload) })
 	case MsgPause:
-		s.handlePause(conn)
+		s.inRoom(c, true, func(r *room) { r.handlePause(c, msg.Payload) })
 	case MsgResume:
-		s.handleResume(conn)
+		s.inRoom(c, true, (*room).handleResume)
+	case MsgUndo:
+		s.inRoom(c, true, func(r *room) { r.handleUndo(c) })
+	case MsgRewind:
+		s.inRoom(c, true, func(r *room) { r.handleRewind(c, msg.Payload) })
+	case MsgEndGame:
+		s.inRoom(c, true, (*room).handleEndGame)
 	default:
-		s.sendError(conn, fmt.Sprintf("Unknown message type: %s", msg.Type))
-	}
-}
-
-func parsePayload[T any](payload interface{}) (*T, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
-	var result T
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, err
+		c.sendError(fmt.Sprintf("Unknown message type: %s", msg.Type))
 	}
-	return &result, nil
-}
-
-func (s *Server) isPaused(gameID string) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.paused[gameID]
 }
 
-func (s *Server) isPendingPause(gameID string) bool {
+// inRoom queues f on the room of c's game. Commands that control the game are refused
+// for spectators.
+func (s *Server) inRoom(c *wsConn, control bool, f func(r *room)) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.pendingPause[gameID]
-}
-
-func (s *Server) activatePendingPause(gameID string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if s.pendingPause[gameID] {
-		s.paused[gameID] = true
-		s.pendingPause[gameID] = false
-		return true
-	}
-	return false
-}
-
-func (s *Server) getGameForConn(conn *websocket.Conn) *game.GameState {
-	s.mu.RLock()
-	gameID := s.clients[conn]
-	gs := s.games[gameID]
+	r := s.games[s.clients[c]]
 	s.mu.RUnlock()
-	return gs
+	if r == nil {
+		c.sendError("No game found. Create a game first.")
+		return
+	}
+	r.do(func() {
+		if control && r.owner != c {
+			c.sendError("Spectators can't control the game")
+			return
+		}
+		if control && r.result != nil {
+			c.sendError("Game is over")
+			return
+		}
+		f(r)
+	})
 }
 
-func (s *Server) sendGameState(conn *websocket.Conn, gs *game.GameState) {
-	showAllCards := gs.Mode == game.ModeTest
-	payload := ConvertGameState(gs, showAllCards)
+// register adds r to the server and makes it c's game, leaving any game c was in before.
+func (s *Server) register(c *wsConn, r *room) {
+	s.leave(c)
 
-	s.send(conn, ServerMessage{
-		Type:    MsgGameState,
-		Payload: payload,
-	})
+	s.mu.Lock()
+	s.games[r.id] = r
+	s.clients[c] = r.id
+	s.mu.Unlock()
 }
 
-func (s *Server) sendActionRequiredIfNeeded(conn *websocket.Conn, gs *game.GameState) {
-	if !gs.IsWaitingForAction() {
-		return
-	}
-
-	player := gs.GetCurrentPlayer()
-	if player == nil {
-		return
-	}
+// leave detaches c from its game, see room.detach.
+func (s *Server) leave(c *wsConn) {
+	s.mu.Lock()
+	r := s.games[s.clients[c]]
+	delete(s.clients, c)
+	s.mu.Unlock()
 
-	validActions := gs.GetValidActions()
-	var vaPayloads []ValidActionPayload
-	for _, va := range validActions {
-		vaPayloads = append(vaPayloads, ValidActionPayload{
-			Type:      va.Type.String(),
-			MinAmount: va.MinAmount,
-			MaxAmount: va.MaxAmount,
-		})
+	if r != nil {
+		r.do(func() { r.detach(c) })
 	}
-
-	s.send(conn, ServerMessage{
-		Type: MsgActionReq,
-		Payload: ActionRequiredPayload{
-			PlayerIdx:    gs.CurrentPlayerIdx,
-			PlayerName:   player.Name,
-			ValidActions: vaPayloads,
-		},
-	})
 }
 
-func (s *Server) sendError(conn *websocket.Conn, message string) {
-	log.Printf("Error: %s", message)
-	s.send(conn, ServerMessage{
-		Type:    MsgError,
-		Payload: ErrorPayload{Message: message},
-	})
-}
-
-func (s *Server) send(conn *websocket.Conn, msg ServerMessage) {
-	data, err := json.Marshal(msg)
+func parsePayload[T any](payload interface{}) (*T, error) {
+	data, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
-		return
+		return nil, err
 	}
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
 
-	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		log.Printf("Error sending message: %v", err)
+func statsPayload(gs *game.GameState) StatsPayload {
+	return StatsPayload{
+		GameID:     gs.ID,
+		HandNumber: gs.HandNumber,
+		Players:    gs.Stats(),
 	}
 }
 
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+func get(t *testing.T, url, token string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func getBody(t *testing.T, url, token string) string {
+	t.Helper()
+	status, body := get(t, url, token)
+	if status != http.StatusOK {
+		t.Fatalf("GET %s: %d\n%s", url, status, body)
+	}
+	return body
+}
+
+// TestHistoryExportVisibility folds the owner's seat in a test mode game and checks
+// which exports show the folded hand.
+func TestHistoryExportVisibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		delayMs int
+	}{
+		{name: "no delay"},
+		{name: "spectator delay", delayMs: 60_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ts := newTestServer(t)
+			owner := dialTest(t, ts)
+			owner.send(MsgNewGame, NewGamePayload{
+				PlayerNames:      []string{"a", "b", "c"},
+				Mode:             "test",
+				Bots:             map[int]string{1: decider.BotStation, 2: decider.BotStation},
+				SpectatorDelayMs: tt.delayMs,
+			})
+			var session SessionPayload
+			owner.readUntil(MsgSession, &session)
+			rm := s.testRoom(t, session.GameID)
+
+			owner.send(MsgStartHand, nil)
+			var ar ActionRequiredPayload
+			owner.readUntil(MsgActionReq, &ar)
+			owner.send(MsgAction, ActionPayload{PlayerIdx: 0, Action: "FOLD", HandNumber: ar.HandNumber, TurnToken: ar.TurnToken})
+			owner.readUntil(MsgHandComplete, nil)
+
+			var folded []game.Card
+			rm.query(func() { folded = rm.gs.CompletedHands()[0].HoleCards(len(rm.gs.Players))[0] })
+			dealt := "Dealt to a [" + folded[0].String() + " " + folded[1].String() + "]"
+			cards := folded[0].String() + folded[1].String()
+
+			base := ts.URL + "/api/games/" + session.GameID + "/history/"
+			for _, token := range session.Tokens {
+				if text := getBody(t, base+"pokerstars", token); !strings.Contains(text, dealt) {
+					t.Errorf("owner export lacks %q:\n%s", dealt, text)
+				}
+				if phh := getBody(t, base+"phh", token); !strings.Contains(phh, cards) {
+					t.Errorf("owner PHH export lacks %s:\n%s", cards, phh)
+				}
+			}
+
+			// A token that isn't the game's is a spectator's
+			text := getBody(t, base+"pokerstars", "spectator")
+			if strings.Contains(text, dealt) {
+				t.Errorf("spectator export shows the folded hand:\n%s", text)
+			}
+			if phh := getBody(t, base+"phh", "spectator"); strings.Contains(phh, cards) {
+				t.Errorf("spectator PHH export shows the folded hand:\n%s", phh)
+			}
+
+			held := tt.delayMs > 0
+			if got := strings.Contains(text, "PokerStars Hand #"); got == held {
+				t.Errorf("spectator export has the hand = %v, want %v:\n%s", got, !held, text)
+			}
+		})
+	}
+}
+
+// TestHandInProgressVisibility checks that /events and /hands/{n} agree on whether the
+// owner and spectators may read a hand while it is played: only in test mode.
+func TestHandInProgressVisibility(t *testing.T) {
+	tests := []struct {
+		mode       string
+		inProgress bool
+	}{
+		{mode: "play"},
+		{mode: "test", inProgress: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			_, ts := newTestServer(t)
+			owner := dialTest(t, ts)
+			owner.send(MsgNewGame, NewGamePayload{
+				PlayerNames: []string{"me", "b", "c"},
+				Mode:        tt.mode,
+				Bots:        map[int]string{1: decider.BotStation, 2: decider.BotStation},
+			})
+			var session SessionPayload
+			owner.readUntil(MsgSession, &session)
+			base := ts.URL + "/api/games/" + session.GameID
+
+			// readable reports whether /events and /hands/1 have hand 1 for token, failing
+			// when they disagree
+			readable := func(token string) bool {
+				t.Helper()
+				var events []game.Event
+				if err := json.Unmarshal([]byte(getBody(t, base+"/events", token)), &events); err != nil {
+					t.Fatal(err)
+				}
+				inEvents := slices.ContainsFunc(events, func(ev game.Event) bool { return ev.HandNumber == 1 })
+				status, body := get(t, base+"/hands/1", token)
+				if status != http.StatusOK && status != http.StatusNotFound {
+					t.Fatalf("GET /hands/1: %d\n%s", status, body)
+				}
+				if inHand := status == http.StatusOK; inHand != inEvents {
+					t.Errorf("/events has hand 1 = %v, /hands/1 = %v", inEvents, inHand)
+				}
+				return inEvents
+			}
+
+			owner.send(MsgStartHand, nil)
+			var ar ActionRequiredPayload
+			owner.readUntil(MsgActionReq, &ar)
+			for _, token := range []string{session.Tokens[0], "spectator"} {
+				if got := readable(token); got != tt.inProgress {
+					t.Errorf("token %s: hand in progress readable = %v, want %v", token, got, tt.inProgress)
+				}
+			}
+
+			owner.send(MsgAction, ActionPayload{PlayerIdx: 0, Action: "FOLD", HandNumber: ar.HandNumber, TurnToken: ar.TurnToken})
+			owner.readUntil(MsgHandComplete, nil)
+			for _, token := range []string{session.Tokens[0], "spectator"} {
+				if !readable(token) {
+					t.Errorf("token %s: finished hand not readable", token)
+				}
+			}
+		})
+	}
+}
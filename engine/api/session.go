@@ -0,0 +1,177 @@
+// Session tokens let a player get their game back after the WebSocket drops. new_game
+// issues a token per human seat (or one for the table in simulate mode, seat -1) in a
+// session message; rejoin with one of them rebinds a new connection as the game's
+// owner and replays the events it missed. While the owner is away, a human seat to act
+// is checked or folded after disconnectTimeout so spectators aren't left waiting.
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+const disconnectTimeout = 30 * time.Second
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// issueSessions creates the game's session tokens, keyed by token.
+func (r *room) issueSessions() map[string]int {
+	sessions := make(map[string]int)
+	for i := range r.gs.Players {
+		if d := r.deciders.Get(i); d == nil || d.Kind() == decider.KindHuman {
+			sessions[newToken()] = i
+		}
+	}
+	if len(sessions) == 0 {
+		sessions[newToken()] = -1
+	}
+	return sessions
+}
+
+// sendSession tells the owner its tokens.
+func (r *room) sendSession() {
+	if r.owner == nil {
+		return
+	}
+	r.owner.send(ServerMessage{
+		Type:    MsgSession,
+		Payload: r.sessionPayload(),
+	})
+}
+
+// sessionPayload lists the game's tokens, seat -> token. sessions never change, so it
+// is safe off the room goroutine.
+func (r *room) sessionPayload() SessionPayload {
+	tokens := make(map[int]string, len(r.sessions))
+	for token, seat := range r.sessions {
+		tokens[seat] = token
+	}
+	return SessionPayload{
+		GameID: r.id,
+		Tokens: tokens,
+	}
+}
+
+func (s *Server) handleRejoin(c *wsConn, payload interface{}) {
+	rp, err := parsePayload[RejoinPayload](payload)
+	if err != nil {
+		c.sendError("Invalid rejoin payload")
+		return
+	}
+
+	s.mu.RLock()
+	r := s.games[rp.GameID]
+	s.mu.RUnlock()
+	if r == nil {
+		c.sendError(fmt.Sprintf("Game %s not found", rp.GameID))
+		return
+	}
+	if _, ok := r.sessions[rp.Token]; !ok {
+		c.sendError("Invalid session token")
+		return
+	}
+
+	s.leave(c)
+	s.mu.Lock()
+	s.clients[c] = rp.GameID
+	s.mu.Unlock()
+
+	r.do(func() {
+		// A stale tab still holding the game loses it
+		if old := r.owner; old != nil && old != c {
+			old.sendError("Game taken over by another connection")
+			s.mu.Lock()
+			delete(s.clients, old)
+			s.mu.Unlock()
+		}
+		r.owner = c
+		delete(r.spectators, c)
+		log.Printf("Game %s: owner rejoined", r.id)
+
+		r.sendSession()
+		vis := r.ownerVisibility()
+		c.send(ServerMessage{
+			Type: MsgMissedEvents,
+			Payload: MissedEventsPayload{
+				Events: redactEvents(r.gs, eventsAfter(r.gs.Events, rp.LastEvent), vis),
+			},
+		})
+		c.send(r.stateFor(c))
+
+		// Ask again for the seat to act, or start the game if nobody was watching it
+		r.next()
+	})
+}
+
+// eventsAfter returns the events after the one with seq last. An event's seq is its
+// index in the log.
+func eventsAfter(events []game.Event, last int) []game.Event {
+	if last+1 >= len(events) {
+		return nil
+	}
+	if last < -1 {
+		last = -1
+	}
+	return events[last+1:]
+}
+
+func (r *room) isHuman(seat int) bool {
+	if seat < 0 || seat >= len(r.gs.Players) {
+		return false
+	}
+	d := r.deciders.Get(seat)
+	return d == nil || d.Kind() == decider.KindHuman
+}
+
+// awaitDisconnected gives an absent owner disconnectTimeout to come back for the human
+// seat to act, then checks or folds for it.
+func (r *room) awaitDisconnected() {
+	version := r.version
+	seat := r.gs.CurrentPlayerIdx
+	time.AfterFunc(disconnectTimeout, func() {
+		r.do(func() {
+			if r.owner != nil || r.version != version || r.paused || r.gs.CurrentPlayerIdx != seat {
+				return
+			}
+			log.Printf("Game %s: seat %d disconnected, acting for it", r.id, seat)
+			r.autoAct(seat, "disconnected")
+		})
+	})
+}
+
+// autoAct checks for seat when it can, folds otherwise. reason is "disconnected" or
+// "timeout" (clock.go).
+func (r *room) autoAct(seat int, reason string) {
+	gs := r.gs
+	action := game.Action{Type: game.ActionFold, PlayerIdx: seat}
+	for _, va := range gs.GetValidActions() {
+		if va.Type == game.ActionCheck {
+			action.Type = game.ActionCheck
+		}
+	}
+	if err := gs.ProcessAction(action); err != nil {
+		log.Printf("Error acting for seat %d: %v", seat, err)
+		return
+	}
+	r.version++
+
+	r.send(ServerMessage{
+		Type: MsgAutoAction,
+		Payload: AutoActionPayload{
+			PlayerIdx: seat,
+			Action:    action.Type.String(),
+			Reason:    reason,
+		},
+	})
+	r.settle()
+}
@@ -0,0 +1,197 @@
+// Which hole cards each client may see. A room renders every broadcast per viewer:
+//   - the owner of a play mode game sees their own seat; in simulate and test mode there
+//     is nobody at the table to ghost for, so the owner sees every seat
+//   - spectators see hole cards only once they are shown down, or every card but only
+//     after the game's spectator delay (NewGamePayload.SpectatorDelayMs)
+//   - admins (joined with ADMIN_TOKEN) see every card instantly
+//
+// LLM reasons often name the hand, so they follow the same rule for spectators.
+package api
+
+import (
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// Visibility is what a rendered message may reveal.
+type Visibility struct {
+	All     bool // Every seat's hole cards
+	Seat    int  // The viewer's own seat, -1 for none
+	Reasons bool // LLM reasons
+}
+
+var (
+	adminVisibility     = Visibility{All: true, Seat: -1, Reasons: true}
+	spectatorVisibility = Visibility{Seat: -1}
+)
+
+// canSee reports whether the viewer may see seat's hole cards in gs.
+func (v Visibility) canSee(gs *game.GameState, seat int) bool {
+	return v.All || seat == v.Seat || shownDown(gs, seat)
+}
+
+// shownDown reports whether seat's cards were tabled: it was still competing for a pot
+// at showdown. Folded hands and uncontested winners stay hidden.
+func shownDown(gs *game.GameState, seat int) bool {
+	if gs.Street != game.StreetShowdown && gs.Street != game.StreetComplete {
+		return false
+	}
+	for _, pot := range gs.Pots {
+		if len(pot.EligiblePlayers) < 2 {
+			continue
+		}
+		for _, idx := range pot.EligiblePlayers {
+			if idx == seat {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *room) ownerVisibility() Visibility {
+	if r.gs.Mode == game.ModePlay {
+		return Visibility{Seat: r.gs.UserSeatIdx, Reasons: true}
+	}
+	return adminVisibility
+}
+
+// delayedMessage is a broadcast held back from spectators until due.
+type delayedMessage struct {
+	due time.Time
+	msg ServerMessage
+}
+
+// delay queues msg, already rendered with every card, for the non-admin spectators.
+func (r *room) delay(msg ServerMessage) {
+	r.delayed = append(r.delayed, delayedMessage{due: time.Now().Add(r.spectatorDelay), msg: msg})
+	if len(r.delayed) == 1 {
+		r.armDelay()
+	}
+}
+
+func (r *room) armDelay() {
+	time.AfterFunc(time.Until(r.delayed[0].due), func() { r.do(r.flushDelayed) })
+}
+
+// flushDelayed sends every delayed message that is due, in order.
+func (r *room) flushDelayed() {
+	now := time.Now()
+	for len(r.delayed) > 0 && !r.delayed[0].due.After(now) {
+		msg := r.delayed[0].msg
+		r.delayed = r.delayed[1:]
+		r.released = msg.Seq
+		if msg.Type == MsgGameState {
+			r.delayedState = &msg
+		}
+		for c, admin := range r.spectators {
+			if !admin {
+				c.send(msg)
+			}
+		}
+	}
+	if len(r.delayed) > 0 {
+		r.armDelay()
+	}
+}
+
+// spectatorState is the game_state a spectator gets on join or get_state.
+func (r *room) spectatorState(admin bool) ServerMessage {
+	var msg ServerMessage
+	switch {
+	case admin:
+		msg = r.stateMessage(adminVisibility)
+		msg.Seq = r.seq
+	case r.spectatorDelay > 0 && r.delayedState != nil:
+		msg = *r.delayedState
+	case r.spectatorDelay > 0:
+		// Nothing has come through the delay yet, so show what everyone may see now
+		msg = r.stateMessage(spectatorVisibility)
+		msg.Seq = r.released
+	default:
+		msg = r.stateMessage(spectatorVisibility)
+		msg.Seq = r.seq
+	}
+	return msg
+}
+
+// spectatorHand is the last hand spectators may look up over HTTP. With a spectator
+// delay that is the last hand finished in what the delay has released, see
+// spectatorState.
+func (r *room) spectatorHand() int {
+	switch {
+	case r.spectatorDelay == 0:
+		return r.gs.HandNumber
+	case r.delayedState == nil:
+		return 0 // Nothing has come through yet
+	}
+	state := r.delayedState.Payload.(GameStatePayload)
+	if state.Street == game.StreetComplete.String() {
+		return state.HandNumber
+	}
+	return state.HandNumber - 1
+}
+
+// lastHand is the last hand whose events a viewer with vis may read over HTTP. Events
+// carry the deck order and every hole card, so admins get every hand; everyone else gets
+// the hand in progress only in test mode, and spectators only what the spectator delay
+// has released.
+func (r *room) lastHand(vis Visibility, spectator bool) int {
+	gs := r.gs
+	if vis.All {
+		return gs.HandNumber
+	}
+	last := gs.HandNumber
+	if gs.Mode != game.ModeTest && !gs.IsHandComplete() {
+		last--
+	}
+	if spectator {
+		last = min(last, r.spectatorHand())
+	}
+	return last
+}
+
+// redactEvents copies events with what vis may not see removed: the deck order, and hole
+// cards unless canSee allows them or they were tabled at the showdown of a finished hand.
+func redactEvents(gs *game.GameState, events []game.Event, vis Visibility) []game.Event {
+	tabled := showdowns(events)
+	out := make([]game.Event, len(events))
+	for i, ev := range events {
+		if !vis.All {
+			ev.Deck = nil
+			if ev.Type == game.EventDeal && ev.PlayerIdx != vis.Seat && !tabled[ev.HandNumber][ev.PlayerIdx] &&
+				(ev.HandNumber != gs.HandNumber || !shownDown(gs, ev.PlayerIdx)) {
+				ev.Cards = nil
+			}
+		}
+		out[i] = ev
+	}
+	return out
+}
+
+// showdowns returns, per hand that finishes in events, the seats whose cards were
+// tabled: every seat dealt in that never folded, when at least two were left.
+func showdowns(events []game.Event) map[int]map[int]bool {
+	inHand := make(map[int]map[int]bool)
+	finished := make(map[int]bool)
+	for _, ev := range events {
+		switch {
+		case ev.Type == game.EventDeal:
+			if inHand[ev.HandNumber] == nil {
+				inHand[ev.HandNumber] = make(map[int]bool)
+			}
+			inHand[ev.HandNumber][ev.PlayerIdx] = true
+		case ev.Type == game.EventAction && ev.Resolved != nil && ev.Resolved.Type == game.ActionFold:
+			delete(inHand[ev.HandNumber], ev.PlayerIdx)
+		case ev.Type == game.EventStreet && ev.Street == game.StreetComplete:
+			finished[ev.HandNumber] = true
+		}
+	}
+	for n, seats := range inHand {
+		if !finished[n] || len(seats) < 2 {
+			delete(inHand, n)
+		}
+	}
+	return inHand
+}
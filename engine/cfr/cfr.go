@@ -0,0 +1,179 @@
+// Package cfr solves small heads-up subgames with counterfactual regret minimization
+// (CFR+), so LLM decisions can be scored against equilibrium frequencies. A subgame is a
+// river betting round: a GameState with the board dealt, a range for each player and an
+// abstraction of bet sizes. tree.go builds the betting tree by playing every abstract
+// action on a copy of the GameState, so the engine's rules decide what is legal;
+// solve.go runs CFR+ with showdowns ranked by EvaluateHand.
+package cfr
+
+import (
+	"fmt"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+const (
+	defaultMaxRaises  = 3
+	defaultIterations = 1000
+)
+
+type Combo struct {
+	Cards  [2]game.Card
+	Weight float64 // Relative likelihood of holding this combo
+}
+
+type Range []Combo
+
+// RangeFromClasses expands hand classes like "AKs" or "99" into a range with every
+// combination weighted equally.
+func RangeFromClasses(classes ...string) (Range, error) {
+	var r Range
+	for _, class := range classes {
+		combos := game.ClassCombos(class)
+		if len(combos) == 0 {
+			return nil, fmt.Errorf("unknown hand class %q", class)
+		}
+		for _, c := range combos {
+			r = append(r, Combo{Cards: c, Weight: 1})
+		}
+	}
+	return r, nil
+}
+
+// without drops combos that use any of the dead cards.
+func (r Range) without(dead []game.Card) Range {
+	var out Range
+next:
+	for _, c := range r {
+		for _, d := range dead {
+			if c.Cards[0] == d || c.Cards[1] == d {
+				continue next
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+type Config struct {
+	State      *game.GameState // Heads-up, on the river, before anyone has bet
+	Ranges     [2]Range        // The first and second player to act
+	BetSizes   []float64       // Bets and raises as fractions of the pot; all-in is always available
+	MaxRaises  int             // Bets and raises per subgame (default 3)
+	Iterations int             // CFR+ iterations (default 1000)
+}
+
+// RiverState builds a heads-up river spot: pot already in the middle and each player
+// with stack behind. Player 0 acts first.
+func RiverState(board []game.Card, pot, stack, bigBlind int) *game.GameState {
+	players := make([]game.Player, 2)
+	for i := range players {
+		players[i] = game.Player{
+			ID:               fmt.Sprintf("%d", i+1),
+			Name:             fmt.Sprintf("P%d", i+1),
+			Stack:            stack,
+			Status:           game.PlayerActive,
+			TotalBetThisHand: pot / 2,
+			SeatPosition:     i,
+		}
+	}
+	players[0].TotalBetThisHand += pot % 2
+	return &game.GameState{
+		Players:          players,
+		CommunityCards:   append([]game.Card{}, board...),
+		Street:           game.StreetRiver,
+		ButtonIdx:        1,
+		CurrentPlayerIdx: 0,
+		MinRaise:         bigBlind,
+		LastRaiseAmount:  bigBlind,
+		Stakes:           game.Stakes{SmallBlind: bigBlind / 2, BigBlind: bigBlind},
+	}
+}
+
+// Solution is the average strategy of a solved subgame.
+type Solution struct {
+	root     *node
+	ranges   [2]Range
+	board    []game.Card
+	pot      int // Chips in the middle before the subgame
+	outcomes [2][][]int8
+}
+
+// Solve builds the subgame's betting tree and runs CFR+ on it.
+func Solve(cfg Config) (*Solution, error) {
+	gs := cfg.State
+	if gs == nil || gs.Street != game.StreetRiver || len(gs.CommunityCards) != 5 {
+		return nil, fmt.Errorf("only river subgames are supported")
+	}
+	if gs.CountActivePlayers() != 2 || gs.CountActiveNonAllInPlayers() != 2 {
+		return nil, fmt.Errorf("subgame must be heads-up with both players able to act")
+	}
+	if gs.CurrentBet != 0 {
+		return nil, fmt.Errorf("subgame must start before anyone has bet")
+	}
+	if cfg.MaxRaises <= 0 {
+		cfg.MaxRaises = defaultMaxRaises
+	}
+	if cfg.Iterations <= 0 {
+		cfg.Iterations = defaultIterations
+	}
+
+	var ranges [2]Range
+	for p := range ranges {
+		ranges[p] = cfg.Ranges[p].without(gs.CommunityCards)
+		if len(ranges[p]) == 0 {
+			return nil, fmt.Errorf("range %d is empty after removing board cards", p)
+		}
+	}
+
+	t := newTree(gs, cfg)
+	s := &Solution{
+		root:   t.build(gs.Clone(), 0),
+		ranges: ranges,
+		board:  gs.CommunityCards,
+		pot:    gs.GetTotalPot(),
+	}
+	s.run(cfg.Iterations)
+	return s, nil
+}
+
+// Strategy returns the equilibrium frequencies of the abstract actions available after
+// history for a player holding hand. Real actions in history are matched to the
+// nearest abstract action.
+func (s *Solution) Strategy(history []game.Action, hand [2]game.Card) ([]game.Action, []float64, error) {
+	n := s.root
+	for _, a := range history {
+		if n.terminal() {
+			return nil, nil, fmt.Errorf("history continues past the end of the subgame")
+		}
+		n = n.children[n.nearest(a)]
+	}
+	if n.terminal() {
+		return nil, nil, fmt.Errorf("history ends the subgame")
+	}
+
+	h := s.handIndex(n.player, hand)
+	if h < 0 {
+		return nil, nil, fmt.Errorf("%s%s is not in player %d's range", hand[0], hand[1], n.player)
+	}
+	return n.actions, n.averageStrategy(h), nil
+}
+
+// Frequency is how often the equilibrium takes the abstract action nearest to action.
+func (s *Solution) Frequency(history []game.Action, hand [2]game.Card, action game.Action) (float64, error) {
+	actions, freqs, err := s.Strategy(history, hand)
+	if err != nil {
+		return 0, err
+	}
+	n := &node{actions: actions}
+	return freqs[n.nearest(action)], nil
+}
+
+func (s *Solution) handIndex(player int, hand [2]game.Card) int {
+	for i, c := range s.ranges[player] {
+		if c.Cards == hand || c.Cards == [2]game.Card{hand[1], hand[0]} {
+			return i
+		}
+	}
+	return -1
+}
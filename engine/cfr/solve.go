@@ -0,0 +1,262 @@
+// This file runs CFR+ over a subgame's tree. Strategies are kept per hand in each
+// player's range and traversals carry the opponent's reach for every hand at once, so a
+// showdown compares whole ranges against each other. Showdown results come from
+// EvaluateHand and CompareHands, computed once per pair of hands.
+package cfr
+
+import "github.com/rizzwareengineer/no-LLMit/engine/game"
+
+// Showdown outcomes for the first hand of a pair
+const (
+	outcomeLose int8 = iota
+	outcomeTie
+	outcomeWin
+	outcomeBlocked // The hands share a card
+)
+
+func (s *Solution) run(iterations int) {
+	s.outcomes = s.showdownOutcomes()
+	s.root.allocate(len(s.ranges[0]), len(s.ranges[1]))
+	for t := 1; t <= iterations; t++ {
+		for p := 0; p < 2; p++ {
+			s.cfr(s.root, p, s.rangeWeights(p), s.rangeWeights(1-p), float64(t))
+		}
+	}
+}
+
+// showdownOutcomes[p][i][j] is the result of player p's hand i against the other
+// player's hand j.
+func (s *Solution) showdownOutcomes() [2][][]int8 {
+	board := s.board
+	var results [2][]game.HandResult
+	for p := range results {
+		results[p] = make([]game.HandResult, len(s.ranges[p]))
+		for i, c := range s.ranges[p] {
+			results[p][i] = game.EvaluateHand(append([]game.Card{c.Cards[0], c.Cards[1]}, board...))
+		}
+	}
+
+	var out [2][][]int8
+	for p := range out {
+		out[p] = make([][]int8, len(s.ranges[p]))
+		for i, a := range s.ranges[p] {
+			out[p][i] = make([]int8, len(s.ranges[1-p]))
+			for j, b := range s.ranges[1-p] {
+				switch cmp := game.CompareHands(results[p][i], results[1-p][j]); {
+				case game.CombosOverlap(a.Cards, b.Cards):
+					out[p][i][j] = outcomeBlocked
+				case cmp > 0:
+					out[p][i][j] = outcomeWin
+				case cmp < 0:
+					out[p][i][j] = outcomeLose
+				default:
+					out[p][i][j] = outcomeTie
+				}
+			}
+		}
+	}
+	return out
+}
+
+func (s *Solution) rangeWeights(p int) []float64 {
+	w := make([]float64, len(s.ranges[p]))
+	for i, c := range s.ranges[p] {
+		w[i] = c.Weight
+	}
+	return w
+}
+
+func (n *node) allocate(hands0, hands1 int) {
+	if n.terminal() {
+		return
+	}
+	hands := hands0
+	if n.player == 1 {
+		hands = hands1
+	}
+	n.regrets = make([][]float64, hands)
+	n.strategySum = make([][]float64, hands)
+	for h := range n.regrets {
+		n.regrets[h] = make([]float64, len(n.actions))
+		n.strategySum[h] = make([]float64, len(n.actions))
+	}
+	for _, c := range n.children {
+		c.allocate(hands0, hands1)
+	}
+}
+
+// currentStrategy is regret matching: actions in proportion to their positive regret.
+func (n *node) currentStrategy(h int) []float64 {
+	return normalize(n.regrets[h])
+}
+
+func (n *node) averageStrategy(h int) []float64 {
+	return normalize(n.strategySum[h])
+}
+
+func normalize(weights []float64) []float64 {
+	out := make([]float64, len(weights))
+	var total float64
+	for _, w := range weights {
+		total += max(w, 0)
+	}
+	for a, w := range weights {
+		if total > 0 {
+			out[a] = max(w, 0) / total
+		} else {
+			out[a] = 1 / float64(len(weights))
+		}
+	}
+	return out
+}
+
+// cfr returns the counterfactual value of each of traverser's hands at n, given the
+// opponent's reach for each of theirs, and updates traverser's regrets. The average
+// strategy is weighted by traverser's own reach and by iteration (linear averaging, as
+// CFR+ does).
+func (s *Solution) cfr(n *node, traverser int, selfReach, oppReach []float64, iteration float64) []float64 {
+	if n.terminal() {
+		return s.terminalValues(n, traverser, oppReach)
+	}
+
+	hands := len(s.ranges[traverser])
+	values := make([]float64, hands)
+	if n.player != traverser {
+		strategies := make([][]float64, len(oppReach))
+		for h := range strategies {
+			strategies[h] = n.currentStrategy(h)
+		}
+		for a, child := range n.children {
+			reach := make([]float64, len(oppReach))
+			for h := range reach {
+				reach[h] = oppReach[h] * strategies[h][a]
+			}
+			for h, v := range s.cfr(child, traverser, selfReach, reach, iteration) {
+				values[h] += v
+			}
+		}
+		return values
+	}
+
+	strategies := make([][]float64, hands)
+	for h := range strategies {
+		strategies[h] = n.currentStrategy(h)
+	}
+	actionValues := make([][]float64, len(n.children))
+	for a, child := range n.children {
+		reach := make([]float64, hands)
+		for h := range reach {
+			reach[h] = selfReach[h] * strategies[h][a]
+		}
+		actionValues[a] = s.cfr(child, traverser, reach, oppReach, iteration)
+		for h := range values {
+			values[h] += strategies[h][a] * actionValues[a][h]
+		}
+	}
+	for h := range values {
+		for a := range n.children {
+			n.regrets[h][a] = max(n.regrets[h][a]+actionValues[a][h]-values[h], 0)
+			n.strategySum[h][a] += iteration * selfReach[h] * strategies[h][a]
+		}
+	}
+	return values
+}
+
+// terminalValues is what each of p's hands wins from the start of the subgame, summed
+// over the opponent's hands weighted by their reach. The pot already in the middle goes
+// to the winner, split on a tie.
+func (s *Solution) terminalValues(n *node, p int, oppReach []float64) []float64 {
+	values := make([]float64, len(s.ranges[p]))
+	matched := float64(min(n.contrib[0], n.contrib[1]))
+	win, lose, tie := float64(s.pot)+matched, -matched, float64(s.pot)/2
+	if n.folder >= 0 {
+		win, lose = float64(s.pot+n.contrib[n.folder]), -float64(n.contrib[n.folder])
+	}
+
+	for i := range values {
+		for j, r := range oppReach {
+			if r == 0 {
+				continue
+			}
+			outcome := s.outcomes[p][i][j]
+			if n.folder >= 0 && outcome != outcomeBlocked {
+				if n.folder == p {
+					outcome = outcomeLose
+				} else {
+					outcome = outcomeWin
+				}
+			}
+			switch outcome {
+			case outcomeWin:
+				values[i] += r * win
+			case outcomeLose:
+				values[i] += r * lose
+			case outcomeTie:
+				values[i] += r * tie
+			}
+		}
+	}
+	return values
+}
+
+// Exploitability is how many chips per hand, averaged over both players, a best
+// response wins beyond the equilibrium's value. It falls toward 0 as the solution
+// converges.
+func (s *Solution) Exploitability() float64 {
+	var total, matchups float64
+	for p := 0; p < 2; p++ {
+		own, opp := s.rangeWeights(p), s.rangeWeights(1-p)
+		for h, v := range s.bestResponse(s.root, p, opp) {
+			total += own[h] * v
+		}
+		if p == 0 {
+			for i := range own {
+				for j := range opp {
+					if s.outcomes[0][i][j] != outcomeBlocked {
+						matchups += own[i] * opp[j]
+					}
+				}
+			}
+		}
+	}
+	if matchups == 0 {
+		return 0
+	}
+	// Best responses of both players against each other's strategy sum to the pot at equilibrium
+	return (total/matchups - float64(s.pot)) / 2
+}
+
+// bestResponse is like cfr with p maximizing each hand's value against the
+// opponent's average strategy, and nothing updated.
+func (s *Solution) bestResponse(n *node, p int, oppReach []float64) []float64 {
+	if n.terminal() {
+		return s.terminalValues(n, p, oppReach)
+	}
+
+	values := make([]float64, len(s.ranges[p]))
+	if n.player != p {
+		strategies := make([][]float64, len(oppReach))
+		for h := range strategies {
+			strategies[h] = n.averageStrategy(h)
+		}
+		for a, child := range n.children {
+			reach := make([]float64, len(oppReach))
+			for h := range reach {
+				reach[h] = oppReach[h] * strategies[h][a]
+			}
+			for h, v := range s.bestResponse(child, p, reach) {
+				values[h] += v
+			}
+		}
+		return values
+	}
+
+	for a, child := range n.children {
+		for h, v := range s.bestResponse(child, p, oppReach) {
+			if a == 0 || v > values[h] {
+				values[h] = v
+			}
+		}
+	}
+	return values
+}
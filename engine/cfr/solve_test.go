@@ -0,0 +1,83 @@
+package cfr
+
+import (
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+func parseCards(t *testing.T, ss ...string) []game.Card {
+	t.Helper()
+	var out []game.Card
+	for _, s := range ss {
+		c, err := game.ParseCard(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// solve solves a river on Ks 8d 5c 2h 3s with 100 in the pot and 300 behind.
+func solve(t *testing.T, ranges [2][]string, betSizes []float64, iterations int) *Solution {
+	t.Helper()
+	var rs [2]Range
+	for p, classes := range ranges {
+		r, err := RangeFromClasses(classes...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rs[p] = r
+	}
+	s, err := Solve(Config{
+		State:      RiverState(parseCards(t, "Ks", "8d", "5c", "2h", "3s"), 100, 300, 10),
+		Ranges:     rs,
+		BetSizes:   betSizes,
+		Iterations: iterations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSolveConverges(t *testing.T) {
+	// Two bet sizes and raises give each player decisions below their first one
+	ranges := [2][]string{
+		{"KK", "88", "AKo", "KQo", "K8s", "A4s", "76s", "T9s", "QJs"},
+		{"AKs", "KQs", "KJo", "88", "55", "A4s", "JTs", "QJo"},
+	}
+	early, late := solve(t, ranges, []float64{0.33, 1}, 20), solve(t, ranges, []float64{0.33, 1}, 1000)
+	if e := late.Exploitability(); e > 0.015 || e >= early.Exploitability() {
+		t.Errorf("exploitability %.4f after 1000 iterations, %.4f after 20", e, early.Exploitability())
+	}
+}
+
+func TestSolvePolarized(t *testing.T) {
+	// Sets or air against bluff catchers, with stacks allowing a pot-sized bet and an all-in
+	s := solve(t, [2][]string{{"KK", "88", "76s", "T9s", "QJs"}, {"AKo", "KQs"}}, []float64{1}, 1000)
+
+	actions, freqs, err := s.Strategy(nil, [2]game.Card(parseCards(t, "Kh", "Kd")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check := freqs[0]; actions[0].Type != game.ActionCheck || check > 0.01 {
+		t.Errorf("sets check %.3f of the time, want never", check)
+	}
+
+	// Bluff catchers call a pot-sized bet as often as the bettor's odds require
+	bet := []game.Action{{Type: game.ActionRaise, Amount: 100}}
+	var calls, combos float64
+	for _, c := range s.ranges[1] {
+		freq, err := s.Frequency(bet, c.Cards, game.Action{Type: game.ActionCall})
+		if err != nil {
+			t.Fatal(err)
+		}
+		calls += freq
+		combos++
+	}
+	if rate := calls / combos; rate < 0.4 || rate > 0.6 {
+		t.Errorf("bluff catchers call a pot-sized bet %.3f of the time, want about 0.5", rate)
+	}
+}
@@ -0,0 +1,147 @@
+// This file builds a subgame's betting tree. Each node's actions come from
+// GetValidActions, narrowed to the configured bet sizes, and each child is the result
+// of ProcessAction on a copy of the parent's state.
+package cfr
+
+import (
+	"math"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type node struct {
+	player   int // 0 or 1 at decisions, -1 at terminals
+	actions  []game.Action
+	children []*node
+
+	folder  int    // At terminals, the player who folded, or -1 for a showdown
+	contrib [2]int // At terminals, chips each player put in during the subgame
+
+	regrets     [][]float64 // [hand][action]
+	strategySum [][]float64 // [hand][action]
+}
+
+func (n *node) terminal() bool {
+	return n.player < 0
+}
+
+// nearest matches a real action to an abstract one: the same type when the tree has
+// it, otherwise the bet or raise whose raise-to total is closest.
+func (n *node) nearest(a game.Action) int {
+	best, bestDist := 0, math.MaxInt
+	for i, b := range n.actions {
+		if b.Type == a.Type && a.Type != game.ActionRaise {
+			return i
+		}
+		aggressive := a.Type == game.ActionRaise || a.Type == game.ActionAllIn
+		if aggressive && (b.Type == game.ActionRaise || b.Type == game.ActionAllIn) {
+			if dist := abs(a.Amount - b.Amount); dist < bestDist {
+				best, bestDist = i, dist
+			}
+		}
+		// Calling all in is the all-in action
+		if a.Type == game.ActionCall && b.Type == game.ActionAllIn && bestDist == math.MaxInt {
+			best = i
+		}
+	}
+	return best
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+type tree struct {
+	cfg    Config
+	seats  [2]int // Player index in the state of the first and second to act
+	stacks [2]int // Stacks at the root
+}
+
+func newTree(gs *game.GameState, cfg Config) *tree {
+	t := &tree{cfg: cfg}
+	t.seats[0] = gs.CurrentPlayerIdx
+	for i, p := range gs.Players {
+		if i != gs.CurrentPlayerIdx && p.Status == game.PlayerActive {
+			t.seats[1] = i
+		}
+	}
+	for p, seat := range t.seats {
+		t.stacks[p] = gs.Players[seat].Stack
+	}
+	return t
+}
+
+func (t *tree) build(gs *game.GameState, raises int) *node {
+	n := &node{player: -1, folder: -1}
+	for p, seat := range t.seats {
+		n.contrib[p] = t.stacks[p] - gs.Players[seat].Stack
+		if gs.Players[seat].Status == game.PlayerFolded {
+			n.folder = p
+		}
+	}
+	if n.folder >= 0 || gs.NeedToAdvanceStreet() {
+		return n
+	}
+
+	n.player = 0
+	if gs.CurrentPlayerIdx == t.seats[1] {
+		n.player = 1
+	}
+	for _, a := range t.abstractActions(gs, raises) {
+		child := gs.Clone()
+		if err := child.ProcessAction(a); err != nil {
+			continue
+		}
+		next := raises
+		if child.CurrentBet > gs.CurrentBet {
+			next++
+		}
+		n.actions = append(n.actions, a)
+		n.children = append(n.children, t.build(child, next))
+	}
+	return n
+}
+
+// abstractActions narrows the legal actions to fold (only facing a bet), check, call,
+// the configured bet sizes and all-in, allowing at most MaxRaises bets and raises.
+func (t *tree) abstractActions(gs *game.GameState, raises int) []game.Action {
+	idx := gs.CurrentPlayerIdx
+	player := gs.Players[idx]
+	toCall := gs.CurrentBet - player.CurrentBet
+	canRaise := raises < t.cfg.MaxRaises
+
+	var actions []game.Action
+	for _, va := range gs.GetValidActions() {
+		switch va.Type {
+		case game.ActionFold:
+			if toCall > 0 {
+				actions = append(actions, game.Action{Type: game.ActionFold, PlayerIdx: idx})
+			}
+		case game.ActionCheck, game.ActionCall:
+			actions = append(actions, game.Action{Type: va.Type, Amount: va.MinAmount, PlayerIdx: idx})
+		case game.ActionRaise:
+			if !canRaise {
+				continue
+			}
+			pot := gs.GetTotalPot() + toCall
+			seen := make(map[int]bool)
+			for _, size := range t.cfg.BetSizes {
+				to := max(gs.CurrentBet+int(size*float64(pot)), va.MinAmount)
+				if to >= va.MaxAmount || seen[to] {
+					continue // All-in is added on its own
+				}
+				seen[to] = true
+				actions = append(actions, game.Action{Type: game.ActionRaise, Amount: to, PlayerIdx: idx})
+			}
+		case game.ActionAllIn:
+			// Without a raise left it is still a way to call a bigger bet
+			if canRaise || toCall >= player.Stack {
+				actions = append(actions, game.Action{Type: game.ActionAllIn, Amount: va.MaxAmount, PlayerIdx: idx})
+			}
+		}
+	}
+	return actions
+}
@@ -1,9 +1,10 @@
-// Called by engine/api/llm_handlers.go during an LLM's turn.
+// Called by engine/decider/llm.go during an LLM's turn.
 // Then sends an HTTP request to llm/app.py to get an LLM's decision/action given the current game state.
 package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,6 +15,11 @@ import (
 var (
 	llmServiceURL = getEnv("LLM_SERVICE_URL", "http://localhost:5001")
 	llmTimeout    = 30 * time.Second
+	healthTimeout = 5 * time.Second
+
+	// Shared so connections to the service are reused. Each call bounds itself with a
+	// context deadline rather than a client-wide timeout.
+	httpClient = &http.Client{}
 )
 
 func getEnv(key, fallback string) string {
@@ -37,7 +43,9 @@ type LLMDecisionResponse struct {
 	LatencyMs int    `json:"latency_ms"`
 }
 
-func GetLLMDecision(playerName string, payload interface{}, mode string) (*LLMDecisionResponse, error) {
+// GetLLMDecision asks the service for a decision. The request is abandoned when ctx is
+// done or after llmTimeout, whichever comes first.
+func GetLLMDecision(ctx context.Context, playerName string, payload interface{}, mode string) (*LLMDecisionResponse, error) {
 	reqBody := LLMDecisionRequest{
 		PlayerName: playerName,
 		Payload:    payload,
@@ -49,10 +57,17 @@ func GetLLMDecision(playerName string, payload interface{}, mode string) (*LLMDe
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpClient := &http.Client{Timeout: llmTimeout}
+	ctx, cancel := context.WithTimeout(ctx, llmTimeout)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/decide", llmServiceURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := httpClient.Post(url, "application/json", bytes.NewBuffer(jsonBody))
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call LLM service: %w", err)
 	}
@@ -70,11 +85,17 @@ func GetLLMDecision(playerName string, payload interface{}, mode string) (*LLMDe
 	return &result, nil
 }
 
-func CheckLLMServiceHealth() error {
-	httpClient := &http.Client{Timeout: 5 * time.Second}
+func CheckLLMServiceHealth(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/health", llmServiceURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
 
-	resp, err := httpClient.Get(url)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("LLM service not reachable: %w", err)
 	}
@@ -86,3 +107,34 @@ func CheckLLMServiceHealth() error {
 
 	return nil
 }
+
+// GetSystemPrompt fetches the system prompt the service sends with every decision
+// (llm/prompts.py), so fine-tuning examples are built with the prompt models are served.
+func GetSystemPrompt(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/system-prompt", llmServiceURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("LLM service not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("LLM service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		SystemPrompt string `json:"system_prompt"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.SystemPrompt, nil
+}
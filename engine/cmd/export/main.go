@@ -0,0 +1,89 @@
+// Command export converts a dataset JSONL file (written by the server when DATASET_PATH
+// is set) into a filtered JSONL or Parquet file for publishing, or into supervised
+// fine-tuning examples for Tinker. SFT examples get their system prompt from the LLM
+// service at $LLM_SERVICE_URL.
+//
+//	go run ./cmd/export -in decisions.jsonl -out gpt4o.parquet -format parquet -model GPT-4o
+//	go run ./cmd/export -in decisions.jsonl -out me.jsonl -format sft -source human -user alice
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/client"
+	"github.com/rizzwareengineer/no-LLMit/engine/dataset"
+)
+
+func main() {
+	in := flag.String("in", os.Getenv("DATASET_PATH"), "dataset JSONL file to read (default $DATASET_PATH)")
+	out := flag.String("out", "", "output file (default stdout)")
+	format := flag.String("format", "jsonl", "output format: jsonl, parquet or sft")
+	source := flag.String("source", "", "only decisions from this source (llm or human)")
+	user := flag.String("user", "", "only human decisions tagged with this user")
+	model := flag.String("model", "", "only decisions by this model (player name)")
+	street := flag.String("street", "", "only decisions on this street (Preflop, Flop, Turn, River)")
+	fromStr := flag.String("from", "", "only decisions at or after this time (YYYY-MM-DD or RFC 3339)")
+	toStr := flag.String("to", "", "only decisions at or before this time (YYYY-MM-DD or RFC 3339)")
+	flag.Parse()
+
+	if *in == "" {
+		log.Fatal("no input file: pass -in or set DATASET_PATH")
+	}
+	from, err := dataset.ParseTime(*fromStr, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	to, err := dataset.ParseTime(*toStr, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	records, err := dataset.ReadJSONLFile(*in)
+	if err != nil {
+		log.Fatalf("Error reading dataset: %v", err)
+	}
+
+	filter := dataset.Filter{Source: *source, User: *user, Model: *model, Street: *street, From: from, To: to}
+	var selected []dataset.Record
+	for _, r := range records {
+		if filter.Match(r) {
+			selected = append(selected, r)
+		}
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Error creating output: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "jsonl":
+		err = dataset.WriteJSONL(w, selected)
+	case "parquet":
+		err = dataset.WriteParquet(w, selected)
+	case "sft":
+		systemPrompt, promptErr := client.GetSystemPrompt(context.Background())
+		if promptErr != nil {
+			log.Fatalf("Error getting the system prompt: %v", promptErr)
+		}
+		var skipped int
+		skipped, err = dataset.WriteSFT(w, selected, systemPrompt)
+		if skipped > 0 {
+			log.Printf("Skipped %d decisions without a prompt or executed action", skipped)
+		}
+	default:
+		log.Fatalf("unknown format %q (use jsonl, parquet or sft)", *format)
+	}
+	if err != nil {
+		log.Fatalf("Error writing dataset: %v", err)
+	}
+	log.Printf("Exported %d of %d decisions", len(selected), len(records))
+}
@@ -0,0 +1,84 @@
+// This file aggregates decision grades (see grading) per model: how often each kind of
+// graded decision matched its reference, and how often the model blundered.
+package dataset
+
+import (
+	"sort"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/grading"
+)
+
+type GradeCounts struct {
+	Graded  int `json:"graded"`
+	Correct int `json:"correct"`
+}
+
+type ModelGrades struct {
+	Model       string                 `json:"model"`
+	Decisions   int                    `json:"decisions"`
+	Blunders    int                    `json:"blunders"`     // Decisions with at least one blunder
+	BlunderRate float64                `json:"blunder_rate"` // Percent of decisions
+	ByBlunder   map[string]int         `json:"by_blunder"`   // grading.Blunder* -> decisions
+	ByKind      map[string]GradeCounts `json:"by_kind"`      // grading.Kind* -> counts
+	Accuracy    float64                `json:"accuracy"`     // Percent of graded decisions that were correct
+}
+
+// SummarizeGrades groups graded records by model, sorted by model name. Records
+// without a grade (written before grading existed) are skipped.
+func SummarizeGrades(records []Record) []ModelGrades {
+	byModel := make(map[string]*ModelGrades)
+	for _, r := range records {
+		if r.Grade == nil {
+			continue
+		}
+		m, ok := byModel[r.Model]
+		if !ok {
+			m = &ModelGrades{
+				Model:     r.Model,
+				ByBlunder: make(map[string]int),
+				ByKind:    make(map[string]GradeCounts),
+			}
+			byModel[r.Model] = m
+		}
+		addGrade(m, r.Grade)
+	}
+
+	out := make([]ModelGrades, 0, len(byModel))
+	for _, m := range byModel {
+		graded, correct := 0, 0
+		for _, c := range m.ByKind {
+			graded += c.Graded
+			correct += c.Correct
+		}
+		m.BlunderRate = percent(m.Blunders, m.Decisions)
+		m.Accuracy = percent(correct, graded)
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+func addGrade(m *ModelGrades, g *grading.Grade) {
+	m.Decisions++
+	if len(g.Blunders) > 0 {
+		m.Blunders++
+	}
+	for _, b := range g.Blunders {
+		m.ByBlunder[b]++
+	}
+	if g.Kind != "" && g.Correct != nil {
+		c := m.ByKind[g.Kind]
+		c.Graded++
+		if *g.Correct {
+			c.Correct++
+		}
+		m.ByKind[g.Kind] = c
+	}
+}
+
+func percent(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(d)
+}
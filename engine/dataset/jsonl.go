@@ -0,0 +1,26 @@
+// This file writes records as JSON Lines, one decision per line, the format HuggingFace
+// datasets load directly.
+package dataset
+
+import (
+	"encoding/json"
+	"io"
+)
+
+func WriteJSONL(w io.Writer, records []Record) error {
+	for _, r := range records {
+		if err := writeJSONLine(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSONLine(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,306 @@
+// This file writes records as a Parquet file using only the standard library: one row
+// group, one uncompressed PLAIN-encoded data page per column, all columns required.
+// Nested values (the prompt payload, the outcome) are flattened, with the prompt stored
+// as a JSON string column so the schema stays flat for HuggingFace's dataset viewer.
+//
+// Format reference: https://parquet.apache.org/docs/file-format/ (metadata is Thrift
+// compact protocol, see thriftWriter below).
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+// Parquet physical and converted types used by the columns below
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+	pageTypeData  = 0
+	codecNone     = 0
+	repRequired   = 0
+)
+
+type parquetColumn struct {
+	name      string
+	kind      int // parquetBoolean, parquetInt64 or parquetByteArray
+	converted int // -1 for none
+	value     func(r Record) any
+}
+
+var parquetColumns = []parquetColumn{
+	{"id", parquetByteArray, convertedUTF8, func(r Record) any { return r.ID }},
+	{"game_id", parquetByteArray, convertedUTF8, func(r Record) any { return r.GameID }},
+	{"hand_number", parquetInt64, -1, func(r Record) any { return int64(r.HandNumber) }},
+	{"source", parquetByteArray, convertedUTF8, func(r Record) any { return r.source() }},
+	{"model", parquetByteArray, convertedUTF8, func(r Record) any { return r.Model }},
+	{"user", parquetByteArray, convertedUTF8, func(r Record) any { return r.User }},
+	{"seat", parquetInt64, -1, func(r Record) any { return int64(r.Seat) }},
+	{"street", parquetByteArray, convertedUTF8, func(r Record) any { return r.Street }},
+	{"time", parquetInt64, convertedTimestampMillis, func(r Record) any { return r.Time.UnixMilli() }},
+	{"prompt", parquetByteArray, convertedUTF8, func(r Record) any { return promptJSON(r) }},
+	{"prompt_tokens", parquetInt64, -1, func(r Record) any { return int64(r.PromptTokens) }},
+	{"action", parquetByteArray, convertedUTF8, func(r Record) any { return r.Response.Action }},
+	{"amount", parquetInt64, -1, func(r Record) any { return int64(r.Response.Amount) }},
+	{"reason", parquetByteArray, convertedUTF8, func(r Record) any { return r.Response.Reason }},
+	{"raw", parquetByteArray, convertedUTF8, func(r Record) any { return r.Response.Raw }},
+	{"latency_ms", parquetInt64, -1, func(r Record) any { return int64(r.Response.LatencyMs) }},
+	{"legal", parquetBoolean, -1, func(r Record) any { return r.Legal }},
+	{"error", parquetByteArray, convertedUTF8, func(r Record) any { return r.Error }},
+	{"executed_action", parquetByteArray, convertedUTF8, func(r Record) any { return r.ExecutedAction }},
+	{"executed_amount", parquetInt64, -1, func(r Record) any { return int64(r.ExecutedAmount) }},
+	{"outcome_net", parquetInt64, -1, func(r Record) any {
+		if r.Outcome == nil {
+			return int64(0)
+		}
+		return int64(r.Outcome.Net)
+	}},
+	{"outcome_won", parquetBoolean, -1, func(r Record) any { return r.Outcome != nil && r.Outcome.Won }},
+	{"grade", parquetByteArray, convertedUTF8, func(r Record) any { return gradeJSON(r) }},
+}
+
+func gradeJSON(r Record) string {
+	if r.Grade == nil {
+		return ""
+	}
+	data, err := json.Marshal(r.Grade)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func promptJSON(r Record) string {
+	if r.Prompt == nil {
+		return ""
+	}
+	data, err := json.Marshal(r.Prompt)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// WriteParquet writes records as a single-row-group Parquet file.
+func WriteParquet(w io.Writer, records []Record) error {
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+
+	chunks := make([]columnChunk, len(parquetColumns))
+	for i, col := range parquetColumns {
+		data := encodePlain(col, records)
+
+		var header thriftWriter
+		header.beginStruct()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, int32(len(data)))
+		header.i32Field(3, int32(len(data)))
+		header.structField(5)
+		header.i32Field(1, int32(len(records)))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		offset := int64(file.Len())
+		file.Write(header.Bytes())
+		file.Write(data)
+		chunks[i] = columnChunk{
+			col:    col,
+			offset: offset,
+			size:   int64(header.Len() + len(data)),
+		}
+	}
+
+	meta := fileMetadata(chunks, int64(len(records)))
+	file.Write(meta)
+	binary.Write(&file, binary.LittleEndian, uint32(len(meta)))
+	file.WriteString("PAR1")
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+type columnChunk struct {
+	col    parquetColumn
+	offset int64
+	size   int64
+}
+
+func encodePlain(col parquetColumn, records []Record) []byte {
+	var buf bytes.Buffer
+	var bits byte
+	for i, r := range records {
+		switch v := col.value(r).(type) {
+		case int64:
+			binary.Write(&buf, binary.LittleEndian, v)
+		case string:
+			binary.Write(&buf, binary.LittleEndian, uint32(len(v)))
+			buf.WriteString(v)
+		case bool:
+			// Booleans are bit-packed, least significant bit first
+			if v {
+				bits |= 1 << (i % 8)
+			}
+			if i%8 == 7 {
+				buf.WriteByte(bits)
+				bits = 0
+			}
+		}
+	}
+	if col.kind == parquetBoolean && len(records)%8 != 0 {
+		buf.WriteByte(bits)
+	}
+	return buf.Bytes()
+}
+
+func fileMetadata(chunks []columnChunk, numRows int64) []byte {
+	var t thriftWriter
+	t.beginStruct()
+	t.i32Field(1, 1) // version
+
+	t.listField(2, thriftStruct, len(chunks)+1) // schema: root + one element per column
+	t.beginStruct()
+	t.stringField(4, "schema")
+	t.i32Field(5, int32(len(chunks)))
+	t.endStruct()
+	for _, c := range chunks {
+		t.beginStruct()
+		t.i32Field(1, int32(c.col.kind))
+		t.i32Field(3, repRequired)
+		t.stringField(4, c.col.name)
+		if c.col.converted >= 0 {
+			t.i32Field(6, int32(c.col.converted))
+		}
+		t.endStruct()
+	}
+
+	t.i64Field(3, numRows)
+
+	var total int64
+	for _, c := range chunks {
+		total += c.size
+	}
+	t.listField(4, thriftStruct, 1) // row_groups
+	t.beginStruct()
+	t.listField(1, thriftStruct, len(chunks)) // columns
+	for _, c := range chunks {
+		t.beginStruct()
+		t.i64Field(2, c.offset) // file_offset
+		t.structField(3)        // meta_data
+		t.i32Field(1, int32(c.col.kind))
+		t.listField(2, thriftI32, 2)
+		t.writeVarint(zigzag32(encodingPlain))
+		t.writeVarint(zigzag32(encodingRLE))
+		t.listField(3, thriftBinary, 1)
+		t.writeString(c.col.name)
+		t.i32Field(4, codecNone)
+		t.i64Field(5, numRows)
+		t.i64Field(6, c.size)
+		t.i64Field(7, c.size)
+		t.i64Field(9, c.offset) // data_page_offset
+		t.endStruct()
+		t.endStruct()
+	}
+	t.i64Field(2, total)
+	t.i64Field(3, numRows)
+	t.endStruct()
+
+	t.stringField(6, "no-LLMit engine")
+	t.endStruct()
+	return t.Bytes()
+}
+
+// Thrift compact protocol type ids
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the subset of the Thrift compact protocol Parquet metadata needs.
+// Field ids are delta-encoded against the previous field of the enclosing struct.
+type thriftWriter struct {
+	bytes.Buffer
+	lastField []int16
+}
+
+func (t *thriftWriter) beginStruct() {
+	t.lastField = append(t.lastField, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.WriteByte(0) // stop
+	t.lastField = t.lastField[:len(t.lastField)-1]
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &t.lastField[len(t.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.writeVarint(zigzag32(int32(id)))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.writeVarint(zigzag32(v))
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.writeVarint(zigzag64(v))
+}
+
+func (t *thriftWriter) stringField(id int16, s string) {
+	t.fieldHeader(id, thriftBinary)
+	t.writeString(s)
+}
+
+// structField starts a nested struct field; close it with endStruct.
+func (t *thriftWriter) structField(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.beginStruct()
+}
+
+// listField writes a list header; the caller writes exactly size elements.
+func (t *thriftWriter) listField(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.WriteByte(0xF0 | elemType)
+		t.writeVarint(uint64(size))
+	}
+}
+
+func (t *thriftWriter) writeString(s string) {
+	t.writeVarint(uint64(len(s)))
+	t.WriteString(s)
+}
+
+func (t *thriftWriter) writeVarint(v uint64) {
+	for v >= 0x80 {
+		t.WriteByte(byte(v) | 0x80)
+		v >>= 7
+	}
+	t.WriteByte(byte(v))
+}
+
+func zigzag32(v int32) uint64 { return uint64(uint32((v << 1) ^ (v >> 31))) }
+func zigzag64(v int64) uint64 { return uint64((v << 1) ^ (v >> 63)) }
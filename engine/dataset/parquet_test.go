@@ -0,0 +1,176 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// thriftReader decodes the Thrift compact protocol written by thriftWriter. Structs
+// come back as field id -> value, lists as []any, integers as int64 and binaries as
+// strings.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (t *thriftReader) byte() byte {
+	b := t.data[t.pos]
+	t.pos++
+	return b
+}
+
+func (t *thriftReader) varint() uint64 {
+	var v uint64
+	for shift := 0; ; shift += 7 {
+		b := t.byte()
+		v |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			return v
+		}
+	}
+}
+
+func (t *thriftReader) zigzag() int64 {
+	v := t.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (t *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1, 2:
+		return typ == 1
+	case thriftI32, thriftI64:
+		return t.zigzag()
+	case thriftBinary:
+		n := int(t.varint())
+		s := string(t.data[t.pos : t.pos+n])
+		t.pos += n
+		return s
+	case thriftList:
+		header := t.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(t.varint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = t.value(header & 0x0F)
+		}
+		return list
+	case thriftStruct:
+		fields := make(map[int16]any)
+		var last int16
+		for {
+			header := t.byte()
+			if header == 0 {
+				return fields
+			}
+			id := last + int16(header>>4)
+			if header>>4 == 0 {
+				id = int16(t.zigzag())
+			}
+			fields[id] = t.value(header & 0x0F)
+			last = id
+		}
+	}
+	panic(fmt.Sprintf("unsupported thrift type %d", typ))
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	// Nine rows so the boolean columns spill into a second byte
+	var records []Record
+	for i := 0; i < 9; i++ {
+		r := Record{
+			ID:             fmt.Sprintf("g/1/%d", i),
+			GameID:         "g",
+			HandNumber:     1,
+			Model:          "GPT-4o",
+			Seat:           i % 3,
+			Street:         game.StreetFlop.String(),
+			Time:           time.UnixMilli(1_700_000_000_000 + int64(i)),
+			Prompt:         &game.LLMPromptPayload{YourName: "GPT-4o", Pot: 10 * i},
+			Response:       Response{Action: "RAISE", Amount: 20 * i, Reason: "ünïcode"},
+			Legal:          i%2 == 0,
+			ExecutedAction: "RAISE",
+			ExecutedAmount: 20 * i,
+		}
+		if i%3 != 0 {
+			r.Outcome = &Outcome{Net: -5 * i, Won: i%3 == 1}
+		}
+		records = append(records, r)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+
+	// PAR1, the column chunks, the footer, its length and PAR1 again
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatal("file doesn't start and end with PAR1")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerStart := len(file) - 8 - footerLen
+	if footerStart < 4 {
+		t.Fatalf("footer length %d doesn't fit in a %d byte file", footerLen, len(file))
+	}
+	footer := &thriftReader{data: file[footerStart : len(file)-8]}
+	meta := footer.value(thriftStruct).(map[int16]any)
+	if footer.pos != footerLen {
+		t.Errorf("footer metadata is %d bytes, the file says %d", footer.pos, footerLen)
+	}
+	if rows := meta[3]; rows != int64(len(records)) {
+		t.Errorf("num_rows = %v, want %d", rows, len(records))
+	}
+
+	schema := meta[2].([]any)
+	if len(schema) != len(parquetColumns)+1 {
+		t.Fatalf("%d schema elements, want the root and %d columns", len(schema), len(parquetColumns))
+	}
+	rowGroups := meta[4].([]any)
+	if len(rowGroups) != 1 {
+		t.Fatalf("%d row groups, want 1", len(rowGroups))
+	}
+	chunks := rowGroups[0].(map[int16]any)[1].([]any)
+
+	for i, col := range parquetColumns {
+		if name := schema[i+1].(map[int16]any)[4]; name != col.name {
+			t.Errorf("schema column %d is %v, want %s", i, name, col.name)
+		}
+
+		chunkMeta := chunks[i].(map[int16]any)[3].(map[int16]any)
+		page := &thriftReader{data: file, pos: int(chunkMeta[9].(int64))}
+		header := page.value(thriftStruct).(map[int16]any)
+		if values := header[5].(map[int16]any)[1]; values != int64(len(records)) {
+			t.Errorf("column %s: page holds %v values, want %d", col.name, values, len(records))
+		}
+		data := bytes.NewReader(file[page.pos : page.pos+int(header[3].(int64))])
+
+		for row, r := range records {
+			var got any
+			switch col.kind {
+			case parquetInt64:
+				var v int64
+				binary.Read(data, binary.LittleEndian, &v)
+				got = v
+			case parquetByteArray:
+				var n uint32
+				binary.Read(data, binary.LittleEndian, &n)
+				s := make([]byte, n)
+				data.Read(s)
+				got = string(s)
+			case parquetBoolean:
+				got = file[page.pos+row/8]>>(row%8)&1 == 1
+			}
+			if want := col.value(r); got != want {
+				t.Errorf("column %s row %d = %v, want %v", col.name, row, got, want)
+			}
+		}
+	}
+}
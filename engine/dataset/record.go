@@ -0,0 +1,310 @@
+// Package dataset records every LLM decision with the exact prompt it was given, so
+// sessions can be published as training datasets (JSONL or Parquet) on HuggingFace.
+// Human decisions in ModePlay are captured with the same prompt, so they can be
+// exported as supervised fine-tuning examples (see sft.go).
+//
+// This file defines the Record type and the Store that collects records. A record is
+// held back until its hand finishes so it can carry the hand's outcome, then graded in
+// the background and appended to the in-memory list and, when configured, a JSONL file
+// that survives restarts.
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/grading"
+)
+
+// Decision sources
+const (
+	SourceLLM   = "llm"
+	SourceHuman = "human"
+	SourceBot   = "bot" // In-process Go bots, see decider
+)
+
+// Response mirrors client.LLMDecisionResponse: what the model answered. For human
+// decisions only Action and Amount are set.
+type Response struct {
+	Action    string `json:"action"`
+	Amount    int    `json:"amount"`
+	Reason    string `json:"reason"`
+	Raw       string `json:"raw"`
+	LatencyMs int    `json:"latency_ms"`
+}
+
+// Outcome is how the hand turned out for the deciding player.
+type Outcome struct {
+	Net int  `json:"net"` // Chips won or lost over the whole hand
+	Won bool `json:"won"`
+}
+
+type Record struct {
+	ID             string                 `json:"id"` // gameID/hand/decision
+	GameID         string                 `json:"game_id"`
+	HandNumber     int                    `json:"hand_number"`
+	Source         string                 `json:"source"`         // SourceLLM, SourceHuman or SourceBot
+	Model          string                 `json:"model"`          // Player name, which maps to a model in llm/registry.py
+	User           string                 `json:"user,omitempty"` // Who made a human decision
+	Seat           int                    `json:"seat"`
+	Street         string                 `json:"street"`
+	Time           time.Time              `json:"time"`
+	Prompt         *game.LLMPromptPayload `json:"prompt"`
+	PromptTokens   int                    `json:"prompt_tokens"` // Estimated, see game.EstimateTokens
+	Response       Response               `json:"response"`
+	Legal          bool                   `json:"legal"`           // Engine accepted the action as given
+	Error          string                 `json:"error,omitempty"` // Why the engine rejected it
+	ExecutedAction string                 `json:"executed_action"` // What was actually played (FOLD if rejected)
+	ExecutedAmount int                    `json:"executed_amount"`
+	Outcome        *Outcome               `json:"outcome,omitempty"`
+	Grade          *grading.Grade         `json:"grade,omitempty"` // See grading.Decision
+	Seq            int                    `json:"-"`               // Event seq of the action taken, see Discard
+}
+
+// source treats records written before human capture existed as LLM decisions.
+func (r Record) source() string {
+	if r.Source == "" {
+		return SourceLLM
+	}
+	return r.Source
+}
+
+// Filter selects records. Zero values match everything.
+type Filter struct {
+	Source string
+	User   string
+	Model  string
+	Street string
+	From   time.Time
+	To     time.Time
+}
+
+func (f Filter) Match(r Record) bool {
+	if f.Source != "" && !strings.EqualFold(f.Source, r.source()) {
+		return false
+	}
+	if f.User != "" && !strings.EqualFold(f.User, r.User) {
+		return false
+	}
+	if f.Model != "" && !strings.EqualFold(f.Model, r.Model) {
+		return false
+	}
+	if f.Street != "" && !strings.EqualFold(f.Street, r.Street) {
+		return false
+	}
+	if !f.From.IsZero() && r.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && r.Time.After(f.To) {
+		return false
+	}
+	return true
+}
+
+// Finished hands waiting to be graded before CompleteHand blocks
+const gradeQueue = 256
+
+type Store struct {
+	pending map[string][]Record // gameID -> decisions in the current hand
+	records []Record
+	file    *os.File
+	grading chan gradeJob  // Finished hands for the grading goroutine, see grade
+	graded  sync.WaitGroup // Hands queued and not yet published, see Flush
+	mu      sync.Mutex
+}
+
+// gradeJob is a finished hand's decisions, with outcomes attached, and its record.
+type gradeJob struct {
+	records []Record
+	hand    game.HandRecord
+}
+
+// NewStore creates a store. If path is not empty, existing records are loaded from
+// that JSONL file and finished records are appended to it.
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		pending: make(map[string][]Record),
+		grading: make(chan gradeJob, gradeQueue),
+	}
+	if path != "" {
+		records, err := ReadJSONLFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		s.records = records
+
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open dataset file: %w", err)
+		}
+		s.file = f
+	}
+	go s.grade()
+	return s, nil
+}
+
+// Add holds a decision until CompleteHand is called for its hand.
+func (s *Store) Add(r Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r.ID = fmt.Sprintf("%s/%d/%d", r.GameID, r.HandNumber, len(s.pending[r.GameID]))
+	s.pending[r.GameID] = append(s.pending[r.GameID], r)
+}
+
+// Discard drops the game's pending decisions from event seq onwards, for actions that
+// will never complete the hand as they saw it: the game was rewound to seq or had the
+// action at seq undone. A seq of 0 drops them all, for a game that ended mid-hand.
+func (s *Store) Discard(gameID string, seq int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []Record
+	for _, r := range s.pending[gameID] {
+		if r.Seq < seq {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		delete(s.pending, gameID)
+		return
+	}
+	s.pending[gameID] = kept
+}
+
+// CompleteHand attaches each player's result for the finished hand to its pending
+// decisions and queues them to be graded and published. Grading can take a while
+// (equities, river solves, the push/fold charts on first use), so it runs on the
+// store's own goroutine and the caller only pays for the outcomes.
+func (s *Store) CompleteHand(gs *game.GameState) {
+	hand, ok := gs.LastHand()
+	if !ok {
+		return
+	}
+
+	winners := make(map[int]bool)
+	for _, w := range gs.Winners {
+		winners[w.PlayerIdx] = true
+	}
+
+	s.mu.Lock()
+	pending := s.pending[gs.ID]
+	delete(s.pending, gs.ID)
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	for i := range pending {
+		r := &pending[i]
+		if r.HandNumber == hand.Number && r.Seat >= 0 && r.Seat < len(gs.Players) {
+			r.Outcome = &Outcome{
+				Net: gs.Players[r.Seat].Stack - hand.Stacks[r.Seat],
+				Won: winners[r.Seat],
+			}
+		}
+	}
+	s.graded.Add(1)
+	s.grading <- gradeJob{records: pending, hand: hand}
+}
+
+// grade grades finished hands one at a time, in the order they finished, and publishes
+// their records.
+func (s *Store) grade() {
+	for job := range s.grading {
+		for i := range job.records {
+			r := &job.records[i]
+			if r.Outcome != nil {
+				r.Grade = grading.Decision(r.Prompt, r.Seat, r.ExecutedAction, job.hand)
+			}
+		}
+		if err := s.publish(job.records); err != nil {
+			log.Printf("Error saving dataset records for %s: %v", job.records[0].GameID, err)
+		}
+		s.graded.Done()
+	}
+}
+
+func (s *Store) publish(records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for _, r := range records {
+		s.records = append(s.records, r)
+		if s.file != nil && err == nil {
+			err = writeJSONLine(s.file, r)
+		}
+	}
+	return err
+}
+
+// Flush waits until every hand completed so far has been graded and published. The
+// server calls it on shutdown so queued records aren't lost.
+func (s *Store) Flush() {
+	s.graded.Wait()
+}
+
+// Records returns the finished records matching f, oldest first.
+func (s *Store) Records(f Filter) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Record
+	for _, r := range s.records {
+		if f.Match(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ReadJSONLFile loads records written by a Store or WriteJSONL.
+func ReadJSONLFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1<<20), 64<<20) // Prompts with long histories are large
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		records = append(records, r)
+	}
+	return records, scanner.Err()
+}
+
+// ParseTime accepts RFC 3339 timestamps or plain dates (YYYY-MM-DD) for filters. With
+// endOfDay, a plain date means the end of that day so "to" ranges include it.
+func ParseTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD or RFC 3339)", s)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,133 @@
+package dataset
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/internal/gametest"
+)
+
+// TestStoreConcurrentGames completes and discards hands of several games at once while
+// records are read, as rooms and HTTP handlers do. Run with -race.
+func TestStoreConcurrentGames(t *testing.T) {
+	const games = 16
+	path := filepath.Join(t.TempDir(), "decisions.jsonl")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hands := make([]*game.GameState, games)
+	for g := range hands {
+		hands[g] = gametest.PlayHand(t, []int{1000, 1000, 1000}, g%3)
+		hands[g].ID = fmt.Sprintf("game-%d", g)
+	}
+
+	stop := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				s.Records(Filter{Source: SourceLLM})
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for g, gs := range hands {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seat, p := range gs.Players {
+				s.Add(Record{
+					GameID:         gs.ID,
+					HandNumber:     gs.HandNumber,
+					Model:          p.Name,
+					Seat:           seat,
+					Street:         game.StreetPreflop.String(),
+					Prompt:         gs.GetLLMPromptPayload(p.Name, nil),
+					ExecutedAction: "CALL",
+				})
+			}
+			// Every other game is rewound before its hand completes
+			if g%2 == 1 {
+				s.Discard(gs.ID, 0)
+			}
+			s.CompleteHand(gs)
+		}()
+	}
+	wg.Wait()
+	s.Flush()
+	close(stop)
+	<-read
+
+	records := s.Records(Filter{})
+	if want := games / 2 * 3; len(records) != want {
+		t.Fatalf("%d records published, want %d", len(records), want)
+	}
+	ids := make(map[string]bool)
+	for _, r := range records {
+		var g int
+		fmt.Sscanf(r.GameID, "game-%d", &g)
+		if g%2 == 1 {
+			t.Errorf("record %s of a discarded hand was published", r.ID)
+		}
+		if ids[r.ID] {
+			t.Errorf("record ID %s published twice", r.ID)
+		}
+		ids[r.ID] = true
+		if r.Outcome == nil || r.Grade == nil {
+			t.Errorf("record %s: outcome %v, grade %v, want both", r.ID, r.Outcome, r.Grade)
+		}
+	}
+
+	saved, err := ReadJSONLFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != len(records) {
+		t.Errorf("%d records saved to the file, want %d", len(saved), len(records))
+	}
+}
+
+// TestStoreDiscardFrom undoes the last of a hand's decisions: only that decision is
+// dropped, and the others are published when the hand completes.
+func TestStoreDiscardFrom(t *testing.T) {
+	s, err := NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := gametest.PlayHand(t, []int{1000, 1000, 1000}, 0)
+	gs.ID = "game"
+
+	var seqs []int
+	for _, ev := range gs.Events {
+		if ev.Type == game.EventAction {
+			seqs = append(seqs, ev.Seq)
+		}
+	}
+	for _, seq := range seqs {
+		s.Add(Record{GameID: gs.ID, HandNumber: gs.HandNumber, Seat: gs.Events[seq].PlayerIdx, Seq: seq, Prompt: &game.LLMPromptPayload{}})
+	}
+	last := seqs[len(seqs)-1]
+	s.Discard(gs.ID, last)
+	s.CompleteHand(gs)
+	s.Flush()
+
+	records := s.Records(Filter{})
+	if len(records) != len(seqs)-1 {
+		t.Fatalf("%d records published, want %d", len(records), len(seqs)-1)
+	}
+	for _, r := range records {
+		if r.Seq >= last {
+			t.Errorf("record %s at seq %d was undone", r.ID, r.Seq)
+		}
+	}
+}
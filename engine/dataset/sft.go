@@ -0,0 +1,82 @@
+// This file writes records as supervised fine-tuning examples in the chat format Tinker
+// expects: one JSON object per line with a "messages" list of role/content turns. The
+// system and user turns reproduce what llm/providers sends the model; the assistant turn
+// is the action actually played, in the response format from llm/prompts.py. The system
+// prompt is passed in rather than copied here: callers fetch it from the LLM service
+// (client.GetSystemPrompt) so it can't drift from what models are served.
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type SFTExample struct {
+	Messages []Message `json:"messages"`
+}
+
+// NewSFTExample builds the training example for r with the given system prompt.
+// Records without a prompt or an executed action cannot be turned into examples.
+func NewSFTExample(r Record, systemPrompt string) (SFTExample, error) {
+	if r.Prompt == nil || r.ExecutedAction == "" {
+		return SFTExample{}, fmt.Errorf("record %s has no prompt or executed action", r.ID)
+	}
+
+	// Same layout as json.dumps(payload, indent=2) in llm/providers
+	var prompt bytes.Buffer
+	enc := json.NewEncoder(&prompt)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.Prompt); err != nil {
+		return SFTExample{}, err
+	}
+
+	return SFTExample{Messages: []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: strings.TrimSuffix(prompt.String(), "\n")},
+		{Role: "assistant", Content: targetResponse(r)},
+	}}, nil
+}
+
+// targetResponse formats the executed action the way the model is asked to answer.
+// Humans give no reason, so theirs omit the REASON line.
+func targetResponse(r Record) string {
+	action := r.ExecutedAction
+	amount := r.ExecutedAmount
+	switch action {
+	case "ALL-IN":
+		action = "ALL_IN"
+		amount = 0
+	case "FOLD", "CHECK", "CALL":
+		amount = 0
+	}
+
+	target := fmt.Sprintf("ACTION: %s\nAMOUNT: %d", action, amount)
+	if r.Response.Reason != "" && r.Legal {
+		target += "\nREASON: " + r.Response.Reason
+	}
+	return target
+}
+
+// WriteSFT writes one example per usable record and returns how many were skipped.
+func WriteSFT(w io.Writer, records []Record, systemPrompt string) (skipped int, err error) {
+	for _, r := range records {
+		example, err := NewSFTExample(r, systemPrompt)
+		if err != nil {
+			skipped++
+			continue
+		}
+		if err := writeJSONLine(w, example); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
@@ -0,0 +1,200 @@
+// This file registers the in-process bots that benchmark LLMs against known baselines:
+// a uniformly random player (random.go), a calling station (station.go), a rule-based
+// tight-aggressive player (tag.go) and a short-stack push/fold player (pushfold.go).
+// Bots see the same LLMPromptPayload an LLM does and need no network access.
+package decider
+
+import (
+	"fmt"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// Bot names for NewGamePayload.Bots
+const (
+	BotRandom   = "random"
+	BotStation  = "station"
+	BotTAG      = "tag"
+	BotPushFold = "pushfold"
+)
+
+func NewBot(name string) (Decider, error) {
+	switch name {
+	case BotRandom:
+		return Random{}, nil
+	case BotStation:
+		return Station{}, nil
+	case BotTAG:
+		return TAG{}, nil
+	case BotPushFold:
+		go PushFoldCharts() // Solve the charts before the first decision needs them
+		return PushFold{}, nil
+	default:
+		return nil, fmt.Errorf("unknown bot %q", name)
+	}
+}
+
+// view is what the bots read from a payload.
+type view struct {
+	payload    *game.LLMPromptPayload
+	hole       []game.Card
+	board      []game.Card
+	stack      int
+	position   string
+	toCall     int
+	bigBlind   int
+	currentBet int // Highest total put in on this street
+	myBet      int // What we have put in on this street
+	raises     int // Raises and all-ins so far on this street
+	callers    int // Calls so far on this street
+	effective  int // Our stack plus bet against the deepest opponent still in the hand
+	opponents  int // Opponents still in the hand
+	actions    map[string]game.LLMValidAction
+}
+
+func newView(payload *game.LLMPromptPayload) (*view, error) {
+	v := &view{
+		payload: payload,
+		actions: make(map[string]game.LLMValidAction),
+	}
+
+	var err error
+	if v.hole, err = parseCards(payload.YourCards); err != nil {
+		return nil, err
+	}
+	if v.board, err = parseCards(payload.CommunityCards); err != nil {
+		return nil, err
+	}
+	if len(v.hole) != 2 {
+		return nil, fmt.Errorf("expected 2 hole cards, got %d", len(v.hole))
+	}
+
+	for _, p := range payload.Players {
+		if p.Name == payload.YourName {
+			v.stack = p.Stack
+			v.position = p.Position
+		}
+	}
+	for _, a := range payload.ValidActions {
+		v.actions[a.Type] = a
+	}
+	switch {
+	case v.can("CALL"):
+		v.toCall = v.actions["CALL"].Amount
+	case !v.can("CHECK"):
+		v.toCall = v.stack // Calling would put us all in
+	}
+
+	// Replay this street's bets. Amounts are raise-to totals; all-ins and calls are
+	// capped by the stack the player had before acting.
+	street := streetName(len(v.board))
+	bets := make(map[string]int)
+	for _, a := range payload.ActionsThisHand {
+		if a.Action == "post" {
+			v.bigBlind = max(v.bigBlind, a.Amount)
+		}
+		if a.Street != street {
+			continue
+		}
+		switch a.Action {
+		case "post":
+			bets[a.Player] = a.Amount
+		case "CALL":
+			bets[a.Player] = min(v.currentBet, bets[a.Player]+a.StackBefore)
+			v.callers++
+		case "RAISE":
+			bets[a.Player] = a.Amount
+			v.raises++
+		case "ALL-IN":
+			bets[a.Player] += a.StackBefore
+			if bets[a.Player] > v.currentBet {
+				v.raises++
+			}
+		}
+		v.currentBet = max(v.currentBet, bets[a.Player])
+	}
+	v.myBet = bets[payload.YourName]
+
+	folded := make(map[string]bool)
+	for _, a := range payload.ActionsThisHand {
+		if a.Action == "FOLD" {
+			folded[a.Player] = true
+		}
+	}
+	deepest := 0
+	for _, p := range payload.Players {
+		if p.Name != payload.YourName && !folded[p.Name] && p.Stack+bets[p.Name] > 0 {
+			deepest = max(deepest, p.Stack+bets[p.Name])
+			v.opponents++
+		}
+	}
+	v.effective = min(v.stack+v.myBet, deepest)
+	return v, nil
+}
+
+func parseCards(strs []string) ([]game.Card, error) {
+	cards := make([]game.Card, 0, len(strs))
+	for _, s := range strs {
+		c, err := game.ParseCard(s)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+func streetName(boardCards int) string {
+	switch boardCards {
+	case 0:
+		return game.StreetPreflop.String()
+	case 3:
+		return game.StreetFlop.String()
+	case 4:
+		return game.StreetTurn.String()
+	default:
+		return game.StreetRiver.String()
+	}
+}
+
+func (v *view) can(action string) bool {
+	_, ok := v.actions[action]
+	return ok
+}
+
+func (v *view) checkOrFold(reason string) *Decision {
+	if v.can("CHECK") {
+		return &Decision{Action: "CHECK", Reason: reason}
+	}
+	return &Decision{Action: "FOLD", Reason: reason}
+}
+
+// call calls, going all in if that is the only way to, or checks if there is nothing to call.
+func (v *view) call(reason string) *Decision {
+	switch {
+	case v.can("CHECK"):
+		return &Decision{Action: "CHECK", Reason: reason}
+	case v.can("CALL"):
+		return &Decision{Action: "CALL", Reason: reason}
+	case v.can("ALL-IN"):
+		return &Decision{Action: "ALL_IN", Reason: reason}
+	default:
+		return &Decision{Action: "FOLD", Reason: reason}
+	}
+}
+
+// raiseTo bets or raises to total, clamped to the legal sizes. Raising everything goes
+// all in, and without a legal raise it calls instead.
+func (v *view) raiseTo(total int, reason string) *Decision {
+	for _, t := range []string{"BET", "RAISE"} {
+		a, ok := v.actions[t]
+		if !ok {
+			continue
+		}
+		if total >= a.Max {
+			return &Decision{Action: "ALL_IN", Reason: reason}
+		}
+		return &Decision{Action: t, Amount: max(total, a.Min), Reason: reason}
+	}
+	return v.call(reason)
+}
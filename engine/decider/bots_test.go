@@ -0,0 +1,139 @@
+package decider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+func TestNewBotUnknown(t *testing.T) {
+	for _, name := range []string{"", "gto", "TAG"} {
+		if d, err := NewBot(name); err == nil {
+			t.Errorf("NewBot(%q) = %T, want an error", name, d)
+		}
+	}
+}
+
+// validActions lists gs's legal actions the way the api offers them to a seat.
+func validActions(gs *game.GameState) []game.LLMValidAction {
+	var out []game.LLMValidAction
+	for _, va := range gs.GetValidActions() {
+		a := game.LLMValidAction{Type: va.Type.String()}
+		switch va.Type {
+		case game.ActionRaise:
+			a.Type = "RAISE"
+			if gs.CurrentBet == 0 {
+				a.Type = "BET"
+			}
+			a.Min, a.Max = va.MinAmount, va.MaxAmount
+		case game.ActionCall:
+			a.Amount = va.MinAmount
+		case game.ActionAllIn:
+			a.Amount = va.MaxAmount
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// legal reports whether d is one of valid, sized within its limits for a bet or raise.
+func legal(d *Decision, valid []game.LLMValidAction) bool {
+	action := d.Action
+	if action == "ALL_IN" {
+		action = "ALL-IN"
+	}
+	for _, a := range valid {
+		if a.Type != action {
+			continue
+		}
+		if action == "BET" || action == "RAISE" {
+			return d.Amount >= a.Min && d.Amount <= a.Max
+		}
+		return true
+	}
+	return false
+}
+
+var decisionTypes = map[string]game.ActionType{
+	"FOLD":   game.ActionFold,
+	"CHECK":  game.ActionCheck,
+	"CALL":   game.ActionCall,
+	"BET":    game.ActionRaise,
+	"RAISE":  game.ActionRaise,
+	"ALL_IN": game.ActionAllIn,
+}
+
+// botsTested are the bots TestBotsPlayLegalActions seats and asks at every decision
+var botsTested = []string{BotRandom, BotStation, BotTAG, BotPushFold}
+
+// TestBotsPlayLegalActions plays hands at deep and short stacks with every bot seated.
+// At each decision every bot must pick a legal action, and the seat's bot's is played.
+func TestBotsPlayLegalActions(t *testing.T) {
+	bots := make([]Decider, len(botsTested))
+	for i, name := range botsTested {
+		d, err := NewBot(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bots[i] = d
+	}
+
+	// A table that goes down to one player is set up again
+	newTable := func() *game.GameState {
+		gs := game.NewGame(game.GameConfig{
+			PlayerNames:    []string{"A", "B", "C", "D"},
+			StartingStacks: []int{2000, 1000, 150, 80},
+			Stakes:         game.Stakes{SmallBlind: 5, BigBlind: 10},
+			Mode:           game.ModeSimulate,
+		})
+		gs.DetermineButton()
+		return gs
+	}
+
+	gs := newTable()
+	decisions := 0
+	for hand := 0; hand < 200; hand++ {
+		if gs.CountPlayersWithChips() < 2 {
+			gs = newTable()
+		}
+		if err := gs.StartHand(); err != nil {
+			t.Fatal(err)
+		}
+		for !gs.IsHandComplete() {
+			if gs.NeedToAdvanceStreet() {
+				if err := gs.AdvanceStreet(); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			seat := gs.CurrentPlayerIdx
+			valid := validActions(gs)
+			payload := gs.GetLLMPromptPayload(gs.Players[seat].Name, valid)
+
+			var played *Decision
+			for i, bot := range bots {
+				d, err := bot.Decide(context.Background(), payload)
+				if err != nil {
+					t.Fatalf("%s: %v", botsTested[i], err)
+				}
+				if !legal(d, valid) {
+					t.Fatalf("%s chose %s %d, not among %+v", botsTested[i], d.Action, d.Amount, valid)
+				}
+				if i == seat%len(bots) {
+					played = d
+				}
+			}
+			decisions++
+
+			action := game.Action{Type: decisionTypes[played.Action], Amount: played.Amount, PlayerIdx: seat}
+			if err := gs.ProcessAction(action); err != nil {
+				t.Fatalf("%s %d for seat %d: %v", played.Action, played.Amount, seat, err)
+			}
+		}
+		gs.EliminateBrokePlayers()
+	}
+	if decisions == 0 {
+		t.Fatal("no decisions were made")
+	}
+}
@@ -0,0 +1,68 @@
+// Package decider abstracts who makes a seat's decisions. The api asks the seat's
+// Decider for an action given the same LLMPromptPayload an LLM receives, so a seat can be
+// an LLM over HTTP (llm.go), a human at the browser (human.go), or an in-process Go bot
+// that needs neither the network nor the Python service.
+package decider
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// Decider kinds
+const (
+	KindLLM   = "llm"
+	KindHuman = "human"
+	KindBot   = "bot"
+)
+
+// Decision uses the LLM response vocabulary: Action is one of FOLD, CHECK, CALL, BET,
+// RAISE, ALL_IN and Amount is the raise-to total for BET/RAISE (0 otherwise).
+type Decision struct {
+	Action    string `json:"action"`
+	Amount    int    `json:"amount"`
+	Reason    string `json:"reason"`
+	Raw       string `json:"raw,omitempty"`        // Unparsed model output, LLMs only
+	LatencyMs int    `json:"latency_ms,omitempty"` // Measured by the LLM service
+}
+
+// A Decider should give up with ctx's error once ctx is done; the api cancels it when the
+// game is paused hard, its client disconnects or the game ends.
+type Decider interface {
+	Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error)
+	Kind() string // KindLLM, KindHuman or KindBot
+}
+
+// Func adapts a plain function into a bot Decider.
+type Func func(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error)
+
+func (f Func) Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error) {
+	return f(ctx, payload)
+}
+
+func (f Func) Kind() string { return KindBot }
+
+// Registry maps seats to their Deciders for one game.
+type Registry struct {
+	seats map[int]Decider
+	mu    sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{seats: make(map[int]Decider)}
+}
+
+func (r *Registry) Set(seat int, d Decider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seats[seat] = d
+}
+
+// Get returns the seat's Decider, or nil if none was registered.
+func (r *Registry) Get(seat int) Decider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.seats[seat]
+}
@@ -0,0 +1,21 @@
+// This file is the Decider for seats played from the browser. Humans answer
+// asynchronously with an "action" message, so Decide never blocks: the api stops
+// at human seats and sends action_required instead of calling it.
+package decider
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+var ErrHumanSeat = errors.New("human seats decide through action messages")
+
+type Human struct{}
+
+func (Human) Kind() string { return KindHuman }
+
+func (Human) Decide(context.Context, *game.LLMPromptPayload) (*Decision, error) {
+	return nil, ErrHumanSeat
+}
@@ -0,0 +1,31 @@
+// This file is the Decider for LLM seats: it sends the payload to the Python LLM service
+// (llm/app.py) through client.GetLLMDecision. The model is chosen by the player's name,
+// see llm/registry.py.
+package decider
+
+import (
+	"context"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/client"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type LLM struct {
+	Mode string // Game mode, used by the service for rate limiting
+}
+
+func (l LLM) Kind() string { return KindLLM }
+
+func (l LLM) Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error) {
+	resp, err := client.GetLLMDecision(ctx, payload.YourName, payload, l.Mode)
+	if err != nil {
+		return nil, err
+	}
+	return &Decision{
+		Action:    resp.Action,
+		Amount:    resp.Amount,
+		Reason:    resp.Reason,
+		Raw:       resp.Raw,
+		LatencyMs: resp.LatencyMs,
+	}, nil
+}
@@ -0,0 +1,204 @@
+// This file is the push/fold bot for short stacks. Below MaxPushFoldBB effective big
+// blinds it only shoves or folds, following heads-up Nash equilibrium charts solved at
+// startup from the class-versus-class equities in game/equity.go. Deeper, or after the
+// flop, it plays like the TAG bot. The charts double as the equilibrium reference for
+// grading shove and call-off decisions.
+package decider
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// MaxPushFoldBB is the deepest effective stack, in big blinds, the charts cover.
+const MaxPushFoldBB = 15
+
+const (
+	pushFoldStep       = 0.5 // Chart resolution in big blinds
+	pushFoldTrials     = 192 // Showdowns per class matchup
+	pushFoldIterations = 400 // Fictitious play iterations per depth
+	pushFoldSeed       = 1   // Fixed so every server plays the same charts
+	pushFoldThreshold  = 0.5 // Minimum equilibrium frequency to count as a push or call
+)
+
+// PushFoldChart holds, per hand class, the deepest effective stack in big blinds at
+// which the equilibrium shoves from the small blind (Push) or calls that shove from the
+// big blind (Call). 0 means never.
+type PushFoldChart struct {
+	Push map[string]float64 `json:"push"`
+	Call map[string]float64 `json:"call"`
+}
+
+func (c *PushFoldChart) ShouldPush(class string, stackBB float64) bool {
+	return stackBB <= c.Push[class]
+}
+
+func (c *PushFoldChart) ShouldCall(class string, stackBB float64) bool {
+	return stackBB <= c.Call[class]
+}
+
+var (
+	pushFoldOnce  sync.Once
+	pushFoldChart *PushFoldChart
+)
+
+// PushFoldCharts returns the charts, solving them on first use (a few seconds).
+func PushFoldCharts() *PushFoldChart {
+	pushFoldOnce.Do(func() {
+		pushFoldChart = solvePushFoldCharts()
+	})
+	return pushFoldChart
+}
+
+func solvePushFoldCharts() *PushFoldChart {
+	classes := game.HandClasses()
+	n := len(classes)
+	combos := make([][][2]game.Card, n)
+	for i, c := range classes {
+		combos[i] = game.ClassCombos(c)
+	}
+
+	// weight[i][j] counts the ways to deal class i against class j (card removal);
+	// equity[i][j] is class i's showdown equity against class j.
+	rng := rand.New(rand.NewSource(pushFoldSeed))
+	weight := make([][]float64, n)
+	equity := make([][]float64, n)
+	for i := range classes {
+		weight[i] = make([]float64, n)
+		equity[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			w := 0
+			for _, a := range combos[i] {
+				for _, b := range combos[j] {
+					if !game.CombosOverlap(a, b) {
+						w++
+					}
+				}
+			}
+			weight[i][j], weight[j][i] = float64(w), float64(w)
+			if w > 0 {
+				e := game.ClassEquity(classes[i], classes[j], pushFoldTrials, rng)
+				equity[i][j], equity[j][i] = e, 1-e
+			}
+		}
+	}
+
+	chart := &PushFoldChart{
+		Push: make(map[string]float64, n),
+		Call: make(map[string]float64, n),
+	}
+	for stack := pushFoldStep * 2; stack <= MaxPushFoldBB; stack += pushFoldStep {
+		push, call := solvePushFold(weight, equity, stack)
+		for i, c := range classes {
+			if push[i] >= pushFoldThreshold {
+				chart.Push[c] = stack
+			}
+			if call[i] >= pushFoldThreshold {
+				chart.Call[c] = stack
+			}
+		}
+	}
+	return chart
+}
+
+// solvePushFold finds the heads-up equilibrium at one effective stack (in big blinds,
+// blinds included) by fictitious play: each side repeatedly best-responds to the
+// other's average strategy. The small blind folds (-0.5) or shoves; the big blind folds
+// (-1) or calls, and a called shove is worth stack*(2*equity-1).
+func solvePushFold(weight, equity [][]float64, stack float64) (push, call []float64) {
+	n := len(weight)
+	push = make([]float64, n)
+	call = make([]float64, n)
+	for i := range push {
+		push[i], call[i] = 1, 1
+	}
+
+	for t := 1; t <= pushFoldIterations; t++ {
+		rate := 1 / float64(t+1)
+		for i := 0; i < n; i++ {
+			var ev, total float64
+			for j := 0; j < n; j++ {
+				w := weight[i][j]
+				ev += w * ((1-call[j])*1 + call[j]*stack*(2*equity[i][j]-1))
+				total += w
+			}
+			if ev/total > -0.5 {
+				push[i] += (1 - push[i]) * rate
+			} else {
+				push[i] -= push[i] * rate
+			}
+		}
+		for j := 0; j < n; j++ {
+			var ev, total float64
+			for i := 0; i < n; i++ {
+				w := weight[i][j] * push[i]
+				ev += w * stack * (2*equity[j][i] - 1)
+				total += w
+			}
+			if total == 0 || ev/total > -1 {
+				call[j] += (1 - call[j]) * rate
+			} else {
+				call[j] -= call[j] * rate
+			}
+		}
+	}
+	return push, call
+}
+
+// PushFoldSpot reports whether the charts apply to a decision: before the flop with at
+// most MaxPushFoldBB effective big blinds.
+func PushFoldSpot(payload *game.LLMPromptPayload) bool {
+	v, err := newView(payload)
+	return err == nil && v.pushFoldSpot()
+}
+
+func (v *view) pushFoldSpot() bool {
+	return len(v.board) == 0 && v.bigBlind > 0 && v.effective <= MaxPushFoldBB*v.bigBlind
+}
+
+type PushFold struct{}
+
+func (PushFold) Kind() string { return KindBot }
+
+func (PushFold) Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error) {
+	v, err := newView(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !v.pushFoldSpot() {
+		return v.tag(), nil
+	}
+	stackBB := float64(v.effective) / float64(v.bigBlind)
+
+	chart := PushFoldCharts()
+	class := game.HandClass(v.hole[0], v.hole[1])
+	if v.raises == 0 {
+		if chart.ShouldPush(class, chartDepth(stackBB, v.opponents)) {
+			return v.raiseTo(v.stack+v.myBet, fmt.Sprintf("push %s at %.1f BB", class, stackBB)), nil
+		}
+		return v.checkOrFold(fmt.Sprintf("%s is outside the %.1f BB push range", class, stackBB)), nil
+	}
+
+	if chart.ShouldCall(class, chartDepth(stackBB, v.raises)) {
+		reason := fmt.Sprintf("call off %s at %.1f BB", class, stackBB)
+		if v.currentBet >= v.effective {
+			return v.call(reason), nil // Nobody left to push out
+		}
+		return v.raiseTo(v.stack+v.myBet, reason), nil
+	}
+	return v.checkOrFold(fmt.Sprintf("%s is outside the %.1f BB calling range", class, stackBB)), nil
+}
+
+// chartDepth is the depth, in big blinds, to look a hand up at against opponents. The
+// charts are heads-up; every extra opponent who can wake up with a hand tightens the
+// range like a proportionally deeper stack would, up to the chart's edge, where the
+// tightest range it has still holds the premiums.
+func chartDepth(stackBB float64, opponents int) float64 {
+	return min(stackBB*float64(max(1, opponents)), MaxPushFoldBB)
+}
@@ -0,0 +1,105 @@
+package decider
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+func TestPushFoldCharts(t *testing.T) {
+	chart := PushFoldCharts()
+	for stack := 1.0; stack <= MaxPushFoldBB; stack += pushFoldStep {
+		for _, class := range []string{"AA", "KK", "AKs", "A2o"} {
+			if !chart.ShouldPush(class, stack) || !chart.ShouldCall(class, stack) {
+				t.Errorf("%s at %.1f BB: push %v, call %v, want both", class, stack,
+					chart.ShouldPush(class, stack), chart.ShouldCall(class, stack))
+			}
+		}
+	}
+	for _, class := range []string{"72o", "32o"} {
+		if chart.ShouldPush(class, 5) || chart.ShouldCall(class, 5) {
+			t.Errorf("%s at 5 BB: push %v, call %v, want neither", class, chart.ShouldPush(class, 5), chart.ShouldCall(class, 5))
+		}
+	}
+}
+
+// TestPushFoldDecide plays the small blind heads-up with 8 big blinds.
+func TestPushFoldDecide(t *testing.T) {
+	// Heads-up, hero is the small blind with 8 BB
+	headsUp := []game.LLMPlayer{
+		{Name: "hero", Seat: 0, Stack: 75, Position: "SB"},
+		{Name: "villain", Seat: 1, Stack: 70, Position: "BB"},
+	}
+	headsUpActions := []game.LLMAction{
+		{Player: "hero", Action: "post", Amount: 5, Street: "preflop", Seat: 0, StackBefore: 80},
+		{Player: "villain", Action: "post", Amount: 10, Street: "preflop", Seat: 1, StackBefore: 80},
+	}
+	headsUpValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 5}, {Type: "RAISE", Min: 20, Max: 80}, {Type: "ALL-IN", Amount: 75}}
+
+	// Four-handed at 6 BB, hero is first to act under the gun
+	open := []game.LLMPlayer{
+		{Name: "hero", Seat: 3, Stack: 60, Position: "UTG"},
+		{Name: "btn", Seat: 0, Stack: 60, Position: "BTN"},
+		{Name: "sb", Seat: 1, Stack: 55, Position: "SB"},
+		{Name: "bb", Seat: 2, Stack: 50, Position: "BB"},
+	}
+	openActions := []game.LLMAction{
+		{Player: "sb", Action: "post", Amount: 5, Street: "preflop", Seat: 1, StackBefore: 60},
+		{Player: "bb", Action: "post", Amount: 10, Street: "preflop", Seat: 2, StackBefore: 60},
+	}
+	openValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 10}, {Type: "RAISE", Min: 20, Max: 60}, {Type: "ALL-IN", Amount: 60}}
+
+	// Four-handed at 8 BB, hero is the big blind after the button shoves and the small
+	// blind shoves over the top
+	shoved := []game.LLMPlayer{
+		{Name: "hero", Seat: 2, Stack: 70, Position: "BB"},
+		{Name: "btn", Seat: 0, Stack: 0, Position: "BTN"},
+		{Name: "sb", Seat: 1, Stack: 0, Position: "SB"},
+		{Name: "utg", Seat: 3, Stack: 80, Position: "UTG"},
+	}
+	shovedActions := []game.LLMAction{
+		{Player: "sb", Action: "post", Amount: 5, Street: "preflop", Seat: 1, StackBefore: 100},
+		{Player: "hero", Action: "post", Amount: 10, Street: "preflop", Seat: 2, StackBefore: 80},
+		{Player: "utg", Action: "FOLD", Street: "preflop", Seat: 3, StackBefore: 80},
+		{Player: "btn", Action: "ALL-IN", Amount: 80, Street: "preflop", Seat: 0, StackBefore: 80},
+		{Player: "sb", Action: "ALL-IN", Amount: 100, Street: "preflop", Seat: 1, StackBefore: 95},
+	}
+	shovedValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 70}}
+
+	tests := []struct {
+		name    string
+		hole    []string
+		players []game.LLMPlayer
+		actions []game.LLMAction
+		valid   []game.LLMValidAction
+		want    []string
+	}{
+		{"heads-up premium", []string{"Ah", "Ad"}, headsUp, headsUpActions, headsUpValid, []string{"ALL_IN"}},
+		{"heads-up trash", []string{"7h", "2c"}, headsUp, headsUpActions, headsUpValid, []string{"FOLD"}},
+		{"multiway open premium", []string{"Ah", "Ad"}, open, openActions, openValid, []string{"ALL_IN"}},
+		{"multiway open strong", []string{"Ks", "Kd"}, open, openActions, openValid, []string{"ALL_IN"}},
+		{"multiway open trash", []string{"7h", "2c"}, open, openActions, openValid, []string{"FOLD"}},
+		{"multiway call premium", []string{"Ah", "Ad"}, shoved, shovedActions, shovedValid, []string{"CALL", "ALL_IN"}},
+		{"multiway call trash", []string{"7h", "2c"}, shoved, shovedActions, shovedValid, []string{"FOLD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := &game.LLMPromptPayload{
+				YourName:        "hero",
+				YourCards:       tt.hole,
+				Players:         tt.players,
+				ActionsThisHand: tt.actions,
+				ValidActions:    tt.valid,
+			}
+			d, err := PushFold{}.Decide(context.Background(), payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Contains(tt.want, d.Action) {
+				t.Errorf("%v: %s (%s), want one of %v", tt.hole, d.Action, d.Reason, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// This file is the random bot: it picks uniformly among the legal actions, and for a bet
+// or raise uniformly among the legal sizes. It is the floor any strategy should beat.
+package decider
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type Random struct{}
+
+func (Random) Kind() string { return KindBot }
+
+func (Random) Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error) {
+	if len(payload.ValidActions) == 0 {
+		return &Decision{Action: "FOLD", Reason: "no legal actions"}, nil
+	}
+
+	a := payload.ValidActions[rand.Intn(len(payload.ValidActions))]
+	d := &Decision{Action: a.Type, Reason: "random"}
+	switch a.Type {
+	case "BET", "RAISE":
+		d.Amount = a.Min + rand.Intn(a.Max-a.Min+1)
+	case "ALL-IN":
+		d.Action = "ALL_IN"
+	}
+	return d, nil
+}
@@ -0,0 +1,21 @@
+// This file is the calling station: it checks when it can and calls any bet, never
+// raising and never folding while a call is possible.
+package decider
+
+import (
+	"context"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type Station struct{}
+
+func (Station) Kind() string { return KindBot }
+
+func (Station) Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error) {
+	v, err := newView(payload)
+	if err != nil {
+		return nil, err
+	}
+	return v.call("calling station"), nil
+}
@@ -0,0 +1,235 @@
+// This file is the tight-aggressive bot. Preflop it plays a hand chart by position: it
+// opens a tight range early and a wider one late, re-raises premiums and folds most
+// hands to a raise. Postflop it rates its hand with EvaluateHand, bets and raises
+// strong hands, bets top pair, calls cheap draws and otherwise checks or folds.
+package decider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+type TAG struct{}
+
+func (TAG) Kind() string { return KindBot }
+
+func (TAG) Decide(ctx context.Context, payload *game.LLMPromptPayload) (*Decision, error) {
+	v, err := newView(payload)
+	if err != nil {
+		return nil, err
+	}
+	return v.tag(), nil
+}
+
+func (v *view) tag() *Decision {
+	if len(v.board) == 0 {
+		return v.tagPreflop()
+	}
+	return v.tagPostflop()
+}
+
+// Preflop chart tiers
+const (
+	tierTrash = iota
+	tierPlayable
+	tierStrong
+	tierPremium
+)
+
+// preflopTier places a hand in the chart: premium QQ+ and AK, strong 99-JJ, AQ, AJs
+// and KQs, playable small pairs, suited aces, suited broadways and connectors down to
+// 98s, and AJo, ATo, KQo, KJo.
+func preflopTier(a, b game.Card) int {
+	hi, lo := max(a.Rank, b.Rank), min(a.Rank, b.Rank)
+	suited := a.Suit == b.Suit
+
+	switch {
+	case hi == lo && hi >= game.Queen, hi == game.Ace && lo == game.King:
+		return tierPremium
+	case hi == lo && hi >= game.Nine,
+		hi == game.Ace && lo == game.Queen,
+		suited && hi == game.Ace && lo == game.Jack,
+		suited && hi == game.King && lo == game.Queen:
+		return tierStrong
+	case hi == lo,
+		suited && hi == game.Ace,
+		suited && lo >= game.Ten,
+		suited && hi-lo == 1 && lo >= game.Eight,
+		hi == game.Ace && lo >= game.Ten,
+		hi == game.King && lo >= game.Jack:
+		return tierPlayable
+	default:
+		return tierTrash
+	}
+}
+
+func latePosition(position string) bool {
+	switch position {
+	case "HJ", "CO", "BTN", "SB":
+		return true
+	default:
+		return false
+	}
+}
+
+func (v *view) tagPreflop() *Decision {
+	tier := preflopTier(v.hole[0], v.hole[1])
+	class := game.HandClass(v.hole[0], v.hole[1])
+
+	switch {
+	case v.raises == 0:
+		if tier >= tierStrong || tier == tierPlayable && latePosition(v.position) {
+			// 3 big blinds plus one per limper
+			return v.raiseTo((3+v.callers)*v.bigBlind, fmt.Sprintf("open %s from %s", class, v.position))
+		}
+		return v.checkOrFold(fmt.Sprintf("%s is outside the %s opening range", class, v.position))
+
+	case tier == tierPremium:
+		return v.raiseTo(3*v.currentBet, fmt.Sprintf("re-raise premium %s", class))
+
+	case v.raises == 1 && tier == tierStrong:
+		return v.call(fmt.Sprintf("call a single raise with %s", class))
+
+	case v.raises == 1 && v.hole[0].Rank == v.hole[1].Rank && v.toCall*20 <= v.stack:
+		return v.call(fmt.Sprintf("set mine with %s", class))
+
+	default:
+		return v.checkOrFold(fmt.Sprintf("%s is too weak against %d raises", class, v.raises))
+	}
+}
+
+// Postflop hand strength
+const (
+	strengthWeak = iota
+	strengthDraw
+	strengthTopPair
+	strengthStrong
+)
+
+func (v *view) tagPostflop() *Decision {
+	strength, desc := v.postflopStrength()
+	pot := v.payload.Pot
+
+	switch strength {
+	case strengthStrong:
+		if v.currentBet == 0 {
+			return v.raiseTo(pot*2/3, "value bet "+desc)
+		}
+		return v.raiseTo(3*v.currentBet, "raise for value with "+desc)
+
+	case strengthTopPair:
+		if v.currentBet == 0 {
+			return v.raiseTo(pot/2, "bet "+desc)
+		}
+		if v.toCall*2 <= pot {
+			return v.call("call with " + desc)
+		}
+		return v.checkOrFold(desc + " is not worth a large bet")
+
+	case strengthDraw:
+		// About 4 to 1 is the price a one-card draw needs
+		if v.toCall*4 <= pot+v.toCall {
+			return v.call("call with " + desc)
+		}
+		return v.checkOrFold(desc + " is not getting the odds")
+
+	default:
+		return v.checkOrFold("nothing worth playing")
+	}
+}
+
+// postflopStrength rates the hole cards against the board. A made hand only counts
+// for what the hole cards add to the board.
+func (v *view) postflopStrength() (int, string) {
+	cards := append(append([]game.Card{}, v.hole...), v.board...)
+	made := game.EvaluateHand(cards)
+	boardType := boardHandType(v.board)
+	desc := made.HandType.String()
+
+	switch {
+	case made.HandType >= game.TwoPair && made.HandType > boardType:
+		return strengthStrong, desc
+	case made.HandType == game.OnePair && boardType == game.HighCard && v.topPair():
+		return strengthTopPair, "top pair or better"
+	case len(v.board) < 5 && v.flushDraw():
+		return strengthDraw, "a flush draw"
+	case len(v.board) < 5 && v.straightDraw():
+		return strengthDraw, "an open-ended straight draw"
+	default:
+		return strengthWeak, desc
+	}
+}
+
+// boardHandType is the hand the board alone makes.
+func boardHandType(board []game.Card) game.HandType {
+	if len(board) >= 5 {
+		return game.EvaluateHand(board).HandType
+	}
+	counts := make(map[game.Rank]int)
+	pairs := 0
+	for _, c := range board {
+		counts[c.Rank]++
+	}
+	for _, n := range counts {
+		switch {
+		case n >= 3:
+			return game.ThreeOfAKind
+		case n == 2:
+			pairs++
+		}
+	}
+	switch pairs {
+	case 0:
+		return game.HighCard
+	case 1:
+		return game.OnePair
+	default:
+		return game.TwoPair
+	}
+}
+
+// topPair reports a pocket pair above the board or a hole card pairing its top card.
+func (v *view) topPair() bool {
+	top := game.Two
+	for _, c := range v.board {
+		top = max(top, c.Rank)
+	}
+	h0, h1 := v.hole[0].Rank, v.hole[1].Rank
+	return h0 == h1 && h0 > top || h0 == top || h1 == top
+}
+
+func (v *view) flushDraw() bool {
+	for _, h := range v.hole {
+		n := 0
+		for _, c := range append(append([]game.Card{}, v.hole...), v.board...) {
+			if c.Suit == h.Suit {
+				n++
+			}
+		}
+		if n == 4 {
+			return true
+		}
+	}
+	return false
+}
+
+// straightDraw reports four consecutive ranks, using a hole card, open at both ends.
+func (v *view) straightDraw() bool {
+	have := make(map[game.Rank]bool)
+	for _, c := range append(append([]game.Card{}, v.hole...), v.board...) {
+		have[c.Rank] = true
+	}
+	for lo := game.Three; lo+3 < game.Ace; lo++ {
+		run, usesHole := true, false
+		for r := lo; r <= lo+3; r++ {
+			run = run && have[r]
+			usesHole = usesHole || v.hole[0].Rank == r || v.hole[1].Rank == r
+		}
+		if run && usesHole {
+			return true
+		}
+	}
+	return false
+}
@@ -67,6 +67,9 @@ func (gs *GameState) ProcessAction(action Action) error {
 		return fmt.Errorf("player %d cannot act (status: %s)", action.PlayerIdx, player.Status)
 	}
 
+	stackBefore := player.Stack
+	potBefore := gs.GetTotalPot()
+
 	var err error
 	switch action.Type {
 	case ActionFold:
@@ -87,7 +90,17 @@ func (gs *GameState) ProcessAction(action Action) error {
 		return err
 	}
 
-	gs.RecordActionForLLMs(player.Name, action.Type.String(), action.Amount)
+	// Record what was executed: a short call becomes an all-in, and fold or check
+	// amounts are whatever the caller sent
+	executed := action
+	if player.LastAction != nil {
+		executed = *player.LastAction
+	}
+	if executed.Type == ActionFold || executed.Type == ActionCheck {
+		executed.Amount = 0
+	}
+	gs.RecordActionForLLMs(action.PlayerIdx, executed.Type.String(), executed.Amount, potBefore, stackBefore)
+	gs.recordAction(action, stackBefore-player.Stack)
 
 	return nil
 }
@@ -0,0 +1,39 @@
+// This file copies a GameState so callers can explore hypothetical lines, such as the
+// cfr package playing every abstract action of a subgame on its own copy, without
+// touching the real game.
+package game
+
+import "slices"
+
+// Clone returns a deep copy of the state. Nothing the copy does, including dealing from
+// its deck, affects the original.
+func (gs *GameState) Clone() *GameState {
+	c := *gs
+	c.Players = make([]Player, len(gs.Players))
+	for i, p := range gs.Players {
+		p.HoleCards = slices.Clone(p.HoleCards)
+		if p.LastAction != nil {
+			last := *p.LastAction
+			p.LastAction = &last
+		}
+		c.Players[i] = p
+	}
+	c.CommunityCards = slices.Clone(gs.CommunityCards)
+	c.Pots = make([]Pot, len(gs.Pots))
+	for i, pot := range gs.Pots {
+		pot.EligiblePlayers = slices.Clone(pot.EligiblePlayers)
+		pot.ContributedBy = slices.Clone(pot.ContributedBy)
+		c.Pots[i] = pot
+	}
+	c.Winners = slices.Clone(gs.Winners)
+	c.Events = slices.Clone(gs.Events)
+	c.statCounts = slices.Clone(gs.statCounts)
+	c.LLMActionsThisHand = slices.Clone(gs.LLMActionsThisHand)
+	c.LLMPreviousHands = slices.Clip(gs.LLMPreviousHands) // Only ever appended to
+	if gs.deck != nil {
+		deck := *gs.deck
+		deck.cards = slices.Clone(gs.deck.cards)
+		c.deck = &deck
+	}
+	return &c
+}
@@ -0,0 +1,108 @@
+// This file builds the decision context section of the LLM prompt: the numbers a player
+// would otherwise have to derive from the raw state (amount to call, pot odds, stack to pot
+// ratio, effective stacks), plus street, position and who is still to act. Each field is
+// switched on separately in ContextConfig so their effect on play can be measured.
+package game
+
+import "math"
+
+type ContextConfig struct {
+	AmountToCall    bool `json:"amountToCall,omitempty"`
+	PotOdds         bool `json:"potOdds,omitempty"`
+	SPR             bool `json:"spr,omitempty"`
+	EffectiveStacks bool `json:"effectiveStacks,omitempty"`
+	Street          bool `json:"street,omitempty"`
+	Position        bool `json:"position,omitempty"`
+	PlayersToAct    bool `json:"playersToAct,omitempty"`
+}
+
+func (c ContextConfig) enabled() bool {
+	return c.AmountToCall || c.PotOdds || c.SPR || c.EffectiveStacks || c.Street || c.Position || c.PlayersToAct
+}
+
+type LLMEffectiveStack struct {
+	Player string `json:"player"`
+	Stack  int    `json:"stack"` // The smaller of the two stacks behind
+}
+
+// LLMDecisionContext is derived from the state at the time of the decision. Only the
+// fields enabled in ContextConfig are set.
+type LLMDecisionContext struct {
+	Street          string              `json:"street,omitempty"`
+	Position        string              `json:"position,omitempty"`
+	AmountToCall    *int                `json:"amountToCall,omitempty"`
+	PotOdds         *float64            `json:"potOdds,omitempty"` // Percent equity needed to call: call / (pot + call)
+	SPR             *float64            `json:"spr,omitempty"`     // Largest effective stack / pot
+	EffectiveStacks []LLMEffectiveStack `json:"effectiveStacks,omitempty"`
+	PlayersToAct    []string            `json:"playersToAct,omitempty"` // In order, after you
+}
+
+// GetLLMDecisionContext returns the context for playerIdx, or nil if cfg enables nothing.
+func (gs *GameState) GetLLMDecisionContext(playerIdx int, cfg ContextConfig) *LLMDecisionContext {
+	if !cfg.enabled() {
+		return nil
+	}
+
+	player := gs.Players[playerIdx]
+	pot := gs.GetTotalPot()
+	toCall := min(max(gs.CurrentBet-player.CurrentBet, 0), player.Stack)
+
+	var effective []LLMEffectiveStack
+	largest := 0
+	for i, p := range gs.Players {
+		if i == playerIdx || (p.Status != PlayerActive && p.Status != PlayerAllIn) {
+			continue
+		}
+		stack := min(player.Stack, p.Stack)
+		effective = append(effective, LLMEffectiveStack{Player: p.Name, Stack: stack})
+		largest = max(largest, stack)
+	}
+
+	ctx := &LLMDecisionContext{}
+	if cfg.Street {
+		ctx.Street = gs.Street.String()
+	}
+	if cfg.Position {
+		ctx.Position = gs.getPositionName(playerIdx)
+	}
+	if cfg.AmountToCall {
+		ctx.AmountToCall = &toCall
+	}
+	if cfg.PotOdds {
+		odds := 0.0
+		if toCall > 0 {
+			odds = roundTo(float64(toCall)*100/float64(pot+toCall), 1)
+		}
+		ctx.PotOdds = &odds
+	}
+	if cfg.SPR && pot > 0 {
+		spr := roundTo(float64(largest)/float64(pot), 2)
+		ctx.SPR = &spr
+	}
+	if cfg.EffectiveStacks {
+		ctx.EffectiveStacks = effective
+	}
+	if cfg.PlayersToAct {
+		ctx.PlayersToAct = gs.playersToActAfter(playerIdx)
+	}
+	return ctx
+}
+
+// playersToActAfter lists, in table order, the players after playerIdx who still have a
+// decision to make in this betting round.
+func (gs *GameState) playersToActAfter(playerIdx int) []string {
+	names := []string{}
+	n := len(gs.Players)
+	for step := 1; step < n; step++ {
+		p := gs.Players[(playerIdx+step)%n]
+		if p.Status == PlayerActive && (!p.HasActedThisRound || p.CurrentBet < gs.CurrentBet) {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func roundTo(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
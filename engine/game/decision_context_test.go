@@ -0,0 +1,124 @@
+package game_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/internal/gametest"
+)
+
+// spotAfter deals a 5/10 hand to A (button), B and C and plays script, dealing the
+// next street whenever a betting round closes.
+func spotAfter(t *testing.T, stacks []int, script ...game.Action) *game.GameState {
+	t.Helper()
+	gs, err := game.Replay(game.GameConfig{
+		PlayerNames:    []string{"A", "B", "C"},
+		StartingStacks: stacks,
+		Stakes:         game.Stakes{SmallBlind: 5, BigBlind: 10},
+		Mode:           game.ModeSimulate,
+	}, []game.Event{{Type: game.EventHandStart, HandNumber: 1, Button: 0, Deck: gametest.OrderedDeck()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range script {
+		action.PlayerIdx = gs.CurrentPlayerIdx
+		if err := gs.ProcessAction(action); err != nil {
+			t.Fatalf("%s for seat %d: %v", action.Type, action.PlayerIdx, err)
+		}
+		if gs.NeedToAdvanceStreet() {
+			if err := gs.AdvanceStreet(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return gs
+}
+
+func TestDecisionContext(t *testing.T) {
+	all := game.ContextConfig{AmountToCall: true, PotOdds: true, SPR: true, EffectiveStacks: true, Street: true, Position: true, PlayersToAct: true}
+	raise := func(to int) game.Action { return game.Action{Type: game.ActionRaise, Amount: to} }
+	ptr := func(v float64) *float64 { return &v }
+	chips := func(v int) *int { return &v }
+
+	tests := []struct {
+		name   string
+		stacks []int
+		script []game.Action
+		want   game.LLMDecisionContext
+	}{
+		{
+			name:   "first to act preflop",
+			stacks: []int{1000, 1000, 1000},
+			want: game.LLMDecisionContext{
+				Street: "preflop", Position: "BTN", AmountToCall: chips(10), PotOdds: ptr(40), SPR: ptr(66.33),
+				EffectiveStacks: []game.LLMEffectiveStack{{Player: "B", Stack: 995}, {Player: "C", Stack: 990}},
+				PlayersToAct:    []string{"B", "C"},
+			},
+		},
+		{
+			name:   "facing a raise",
+			stacks: []int{1000, 1000, 1000},
+			script: []game.Action{raise(30)},
+			want: game.LLMDecisionContext{
+				Street: "preflop", Position: "SB", AmountToCall: chips(25), PotOdds: ptr(35.7), SPR: ptr(22),
+				EffectiveStacks: []game.LLMEffectiveStack{{Player: "A", Stack: 970}, {Player: "C", Stack: 990}},
+				PlayersToAct:    []string{"C"},
+			},
+		},
+		{
+			name:   "checked to on the flop",
+			stacks: []int{1000, 1000, 1000},
+			script: []game.Action{raise(30), {Type: game.ActionFold}, {Type: game.ActionCall}},
+			want: game.LLMDecisionContext{
+				Street: "flop", Position: "BB", AmountToCall: chips(0), PotOdds: ptr(0), SPR: ptr(14.92),
+				EffectiveStacks: []game.LLMEffectiveStack{{Player: "A", Stack: 970}},
+				PlayersToAct:    []string{"A"},
+			},
+		},
+		{
+			name:   "facing an all-in",
+			stacks: []int{1000, 1000, 1000},
+			script: []game.Action{{Type: game.ActionAllIn}},
+			want: game.LLMDecisionContext{
+				Street: "preflop", Position: "SB", AmountToCall: chips(995), PotOdds: ptr(49.5), SPR: ptr(0.98),
+				EffectiveStacks: []game.LLMEffectiveStack{{Player: "A", Stack: 0}, {Player: "C", Stack: 990}},
+				PlayersToAct:    []string{"C"},
+			},
+		},
+		{
+			name:   "short stack, calling puts it all in",
+			stacks: []int{1000, 1000, 25},
+			script: []game.Action{raise(100), {Type: game.ActionFold}},
+			want: game.LLMDecisionContext{
+				Street: "preflop", Position: "BB", AmountToCall: chips(15), PotOdds: ptr(11.5), SPR: ptr(0.13),
+				EffectiveStacks: []game.LLMEffectiveStack{{Player: "A", Stack: 15}},
+				PlayersToAct:    []string{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := spotAfter(t, tt.stacks, tt.script...)
+			got := gs.GetLLMDecisionContext(gs.CurrentPlayerIdx, all)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %s\nwant %s", formatContext(*got), formatContext(tt.want))
+			}
+		})
+	}
+
+	gs := spotAfter(t, []int{1000, 1000, 1000})
+	if ctx := gs.GetLLMDecisionContext(gs.CurrentPlayerIdx, game.ContextConfig{}); ctx != nil {
+		t.Errorf("context with nothing enabled = %+v, want nil", ctx)
+	}
+	only := gs.GetLLMDecisionContext(gs.CurrentPlayerIdx, game.ContextConfig{PotOdds: true})
+	if want := (game.LLMDecisionContext{PotOdds: ptr(40)}); !reflect.DeepEqual(*only, want) {
+		t.Errorf("pot odds only = %s, want %s", formatContext(*only), formatContext(want))
+	}
+}
+
+func formatContext(c game.LLMDecisionContext) string {
+	data, _ := json.Marshal(c)
+	return string(data)
+}
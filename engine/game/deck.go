@@ -1,6 +1,7 @@
 // This file manages a standard 52-card deck. NewDeck creates and shuffles it, Deal pulls
 // cards off the top, Burn discards one (per poker rules before community cards), and Reset
-// reshuffles for the next hand. Called by game.go during StartHand and street transitions.
+// reshuffles for the next hand. Order and Stack capture and restore the exact card order so
+// events.go can replay a hand. Called by game.go during StartHand and street transitions.
 package game
 
 import (
@@ -61,3 +62,17 @@ func (d *Deck) Burn() {
 func (d *Deck) Reset() {
 	d.Shuffle()
 }
+
+// Order returns a copy of the deck in its current shuffled order.
+func (d *Deck) Order() []Card {
+	cards := make([]Card, len(d.cards))
+	copy(cards, d.cards)
+	return cards
+}
+
+// Stack replaces the deck with a fixed card order (used when replaying a hand).
+func (d *Deck) Stack(cards []Card) {
+	d.cards = make([]Card, len(cards))
+	copy(d.cards, cards)
+	d.index = 0
+}
@@ -0,0 +1,309 @@
+// This file estimates equity: a hand's expected share of the pot at showdown. EvaluateHand
+// builds and sorts all 21 five-card combinations, far too slow for the millions of
+// showdowns an equity table needs, so strength7 ranks up to 7 cards directly with rank
+// bitmasks. It orders hands exactly as EvaluateHand does. Hand classes ("AKs", see
+// hand_class.go) expand to their card combinations for class-versus-class equities.
+package game
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// Equity estimates hero's share of the pot against villain by dealing out the rest of
+// the board trials times. Ties count as half. With a complete board it is exact.
+func Equity(hero, villain [2]Card, board []Card, trials int, rng *rand.Rand) float64 {
+	dead := append([]Card{hero[0], hero[1], villain[0], villain[1]}, board...)
+	if len(board) == 5 {
+		trials = 1
+	}
+
+	stub := remainingCards(dead)
+	cards := make([]Card, 7)
+	copy(cards[2:], board)
+	var won float64
+	for t := 0; t < trials; t++ {
+		// Partial Fisher-Yates shuffle for the missing board cards
+		for i := len(board); i < 5; i++ {
+			j := i - len(board) + rng.Intn(len(stub)-(i-len(board)))
+			stub[i-len(board)], stub[j] = stub[j], stub[i-len(board)]
+			cards[2+i] = stub[i-len(board)]
+		}
+		cards[0], cards[1] = hero[0], hero[1]
+		h := strength7(cards)
+		cards[0], cards[1] = villain[0], villain[1]
+		v := strength7(cards)
+		switch {
+		case h > v:
+			won++
+		case h == v:
+			won += 0.5
+		}
+	}
+	return won / float64(trials)
+}
+
+// ClassEquity estimates class a's equity against class b preflop over about trials
+// showdowns, sampling a random pair of non-overlapping combinations for every
+// boardsPerCombo boards.
+func ClassEquity(a, b string, trials int, rng *rand.Rand) float64 {
+	const boardsPerCombo = 8
+	as, bs := ClassCombos(a), ClassCombos(b)
+	if len(as) == 0 || len(bs) == 0 {
+		return 0
+	}
+
+	samples := max(1, trials/boardsPerCombo)
+	var total float64
+	for t := 0; t < samples; t++ {
+		var hero, villain [2]Card
+		for {
+			hero, villain = as[rng.Intn(len(as))], bs[rng.Intn(len(bs))]
+			if !CombosOverlap(hero, villain) {
+				break
+			}
+		}
+		total += Equity(hero, villain, nil, boardsPerCombo, rng)
+	}
+	return total / float64(samples)
+}
+
+// MultiwayEquity is hero's share of the pot against every villain hand at once. From
+// the flop on it enumerates every runout exactly; preflop it deals trials random boards.
+func MultiwayEquity(hero [2]Card, villains [][2]Card, board []Card, trials int, rng *rand.Rand) float64 {
+	dead := append([]Card{hero[0], hero[1]}, board...)
+	for _, v := range villains {
+		dead = append(dead, v[0], v[1])
+	}
+	stub := remainingCards(dead)
+	missing := 5 - len(board)
+
+	cards := make([]Card, 7)
+	copy(cards[2:], board)
+	var won float64
+	runouts := 0
+	showdown := func() {
+		runouts++
+		cards[0], cards[1] = hero[0], hero[1]
+		best := strength7(cards)
+		ties := 1
+		for _, v := range villains {
+			cards[0], cards[1] = v[0], v[1]
+			switch s := strength7(cards); {
+			case s > best:
+				return
+			case s == best:
+				ties++
+			}
+		}
+		won += 1 / float64(ties)
+	}
+
+	switch missing {
+	case 0:
+		showdown()
+	case 1:
+		for _, c := range stub {
+			cards[6] = c
+			showdown()
+		}
+	case 2:
+		for i := range stub {
+			for j := i + 1; j < len(stub); j++ {
+				cards[5], cards[6] = stub[i], stub[j]
+				showdown()
+			}
+		}
+	default:
+		for t := 0; t < trials; t++ {
+			for i := 0; i < missing; i++ {
+				j := i + rng.Intn(len(stub)-i)
+				stub[i], stub[j] = stub[j], stub[i]
+				cards[2+len(board)+i] = stub[i]
+			}
+			showdown()
+		}
+	}
+	if runouts == 0 {
+		return 0
+	}
+	return won / float64(runouts)
+}
+
+// IsNuts reports whether no other two cards make a better hand on this board right now.
+// Before the flop only aces are the nuts.
+func IsNuts(hole [2]Card, board []Card) bool {
+	if len(board) < 3 {
+		return hole[0].Rank == Ace && hole[1].Rank == Ace
+	}
+	cards := append([]Card{hole[0], hole[1]}, board...)
+	mine := strength7(cards)
+	stub := remainingCards(cards)
+	for i := range stub {
+		for j := i + 1; j < len(stub); j++ {
+			cards[0], cards[1] = stub[i], stub[j]
+			if strength7(cards) > mine {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// HandClasses lists the 169 preflop hand classes: pairs, then suited and offsuit hands,
+// from the highest ranks down.
+func HandClasses() []string {
+	var classes []string
+	for hi := Ace; hi >= Two; hi-- {
+		classes = append(classes, hi.String()+hi.String())
+	}
+	for hi := Ace; hi >= Two; hi-- {
+		for lo := hi - 1; lo >= Two; lo-- {
+			classes = append(classes, hi.String()+lo.String()+"s", hi.String()+lo.String()+"o")
+		}
+	}
+	return classes
+}
+
+// ClassCombos expands a class into its card combinations: 6 for a pair, 4 suited, 12
+// offsuit. An unknown class has none.
+func ClassCombos(class string) [][2]Card {
+	if len(class) < 2 || len(class) > 3 {
+		return nil
+	}
+	hi, err1 := ParseCard(class[:1] + "h")
+	lo, err2 := ParseCard(class[1:2] + "h")
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	var combos [][2]Card
+	for s1 := Hearts; s1 <= Spades; s1++ {
+		for s2 := Hearts; s2 <= Spades; s2++ {
+			a, b := Card{Rank: hi.Rank, Suit: s1}, Card{Rank: lo.Rank, Suit: s2}
+			if HandClass(a, b) == class && (hi.Rank != lo.Rank || s1 < s2) {
+				combos = append(combos, [2]Card{a, b})
+			}
+		}
+	}
+	return combos
+}
+
+// CombosOverlap reports whether two hands share a card.
+func CombosOverlap(a, b [2]Card) bool {
+	return a[0] == b[0] || a[0] == b[1] || a[1] == b[0] || a[1] == b[1]
+}
+
+func remainingCards(dead []Card) []Card {
+	var cards []Card
+	for suit := Hearts; suit <= Spades; suit++ {
+	next:
+		for rank := Two; rank <= Ace; rank++ {
+			c := Card{Rank: rank, Suit: suit}
+			for _, d := range dead {
+				if c == d {
+					continue next
+				}
+			}
+			cards = append(cards, c)
+		}
+	}
+	return cards
+}
+
+// strength7 ranks 5 to 7 cards: the HandType in the top bits, then up to five ranks
+// that break ties, highest first. Larger is better.
+func strength7(cards []Card) uint32 {
+	var suits [4]uint16
+	var counts [15]uint8
+	var all uint16
+	for _, c := range cards {
+		bit := uint16(1) << c.Rank
+		suits[c.Suit] |= bit
+		counts[c.Rank]++
+		all |= bit
+	}
+
+	for _, mask := range suits {
+		if bits.OnesCount16(mask) < 5 {
+			continue
+		}
+		if hi := straightHigh(mask); hi != 0 {
+			if hi == Ace {
+				return strengthValue(RoyalFlush, hi)
+			}
+			return strengthValue(StraightFlush, hi)
+		}
+		return strengthValue(Flush, topRanks(mask, 5)...)
+	}
+
+	var quad, trips, pair1, pair2 Rank
+	for r := Ace; r >= Two; r-- {
+		switch n := counts[r]; {
+		case n == 4:
+			quad = r
+		case n == 3 && trips == 0:
+			trips = r
+		case n >= 2 && pair1 == 0:
+			pair1 = r
+		case n >= 2 && pair2 == 0:
+			pair2 = r
+		}
+	}
+
+	switch {
+	case quad != 0:
+		return strengthValue(FourOfAKind, append([]Rank{quad}, topRanks(all&^(1<<quad), 1)...)...)
+	case trips != 0 && pair1 != 0:
+		return strengthValue(FullHouse, trips, pair1)
+	}
+	if hi := straightHigh(all); hi != 0 {
+		return strengthValue(Straight, hi)
+	}
+	switch {
+	case trips != 0:
+		return strengthValue(ThreeOfAKind, append([]Rank{trips}, topRanks(all&^(1<<trips), 2)...)...)
+	case pair2 != 0:
+		return strengthValue(TwoPair, append([]Rank{pair1, pair2}, topRanks(all&^(1<<pair1|1<<pair2), 1)...)...)
+	case pair1 != 0:
+		return strengthValue(OnePair, append([]Rank{pair1}, topRanks(all&^(1<<pair1), 3)...)...)
+	default:
+		return strengthValue(HighCard, topRanks(all, 5)...)
+	}
+}
+
+func strengthValue(t HandType, ranks ...Rank) uint32 {
+	v := uint32(t)
+	for i := 0; i < 5; i++ {
+		v <<= 4
+		if i < len(ranks) {
+			v |= uint32(ranks[i])
+		}
+	}
+	return v
+}
+
+// straightHigh returns the top rank of the highest straight in a rank mask, or 0.
+func straightHigh(mask uint16) Rank {
+	if mask&(1<<Ace) != 0 {
+		mask |= 1 << 1 // The ace also plays low in the wheel
+	}
+	for hi := Ace; hi >= Five; hi-- {
+		run := uint16(0x1F) << (hi - 4)
+		if mask&run == run {
+			return hi
+		}
+	}
+	return 0
+}
+
+// topRanks returns the n highest ranks in a rank mask.
+func topRanks(mask uint16, n int) []Rank {
+	ranks := make([]Rank, 0, n)
+	for r := Ace; r >= Two && len(ranks) < n; r-- {
+		if mask&(1<<r) != 0 {
+			ranks = append(ranks, r)
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,164 @@
+// This file records every state transition as an immutable Event: hand start (button and
+// deck order), blind posts, hole cards, player actions, street changes and pot awards.
+// The log is the source of truth for a game. Replay rebuilds a GameState from it, which
+// powers Undo and RewindTo in test mode and the hand history exporters.
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventHandStart EventType = "hand_start"
+	EventPost      EventType = "post"
+	EventDeal      EventType = "deal"
+	EventAction    EventType = "action"
+	EventStreet    EventType = "street"
+	EventAward     EventType = "award"
+)
+
+// Event is one entry in GameState.Events. Seq is the event's index in the log.
+// Only hand_start, action and street events drive Replay; post, deal and award
+// events are derived from them and kept for history exports.
+type Event struct {
+	Seq        int       `json:"seq"`
+	Type       EventType `json:"type"`
+	HandNumber int       `json:"handNumber"`
+	Time       time.Time `json:"time"`
+	Street     Street    `json:"street"`
+	PlayerIdx  int       `json:"playerIdx"`           // -1 when no player is involved
+	Amount     int       `json:"amount,omitempty"`    // Chips moved: blind, chips put in by an action, or pot won
+	Cards      []Card    `json:"cards,omitempty"`     // Hole cards (deal) or newly dealt board cards (street)
+	Action     *Action   `json:"action,omitempty"`    // Action as submitted to ProcessAction
+	Resolved   *Action   `json:"resolved,omitempty"`  // Action as executed (e.g. a short call becomes all-in)
+	Button     int       `json:"button,omitempty"`    // hand_start only
+	Stacks     []int     `json:"stacks,omitempty"`    // hand_start only: stacks before blinds
+	Deck       []Card    `json:"deck,omitempty"`      // hand_start only: shuffled deck order
+	HandDesc   string    `json:"handDesc,omitempty"`  // award only
+	PotNumber  int       `json:"potNumber,omitempty"` // award only
+}
+
+func (gs *GameState) recordEvent(ev Event) {
+	ev.Seq = len(gs.Events)
+	ev.HandNumber = gs.HandNumber
+	ev.Street = gs.Street
+	ev.Time = time.Now()
+	gs.Events = append(gs.Events, ev)
+}
+
+func (gs *GameState) recordHandStart(deck []Card) {
+	stacks := make([]int, len(gs.Players))
+	for i, p := range gs.Players {
+		stacks[i] = p.Stack
+	}
+	gs.Street = StreetPreflop
+	gs.recordEvent(Event{
+		Type:      EventHandStart,
+		PlayerIdx: -1,
+		Button:    gs.ButtonIdx,
+		Stacks:    stacks,
+		Deck:      deck,
+	})
+}
+
+func (gs *GameState) recordAction(action Action, chips int) {
+	submitted := action
+	var resolved *Action
+	if last := gs.Players[action.PlayerIdx].LastAction; last != nil {
+		cpy := *last
+		resolved = &cpy
+	}
+	gs.recordEvent(Event{
+		Type:      EventAction,
+		PlayerIdx: action.PlayerIdx,
+		Amount:    chips,
+		Action:    &submitted,
+		Resolved:  resolved,
+	})
+}
+
+func (gs *GameState) recordStreet(newCards []Card) {
+	cards := make([]Card, len(newCards))
+	copy(cards, newCards)
+	gs.recordEvent(Event{Type: EventStreet, PlayerIdx: -1, Cards: cards})
+
+	if !gs.IsHandComplete() {
+		return
+	}
+	for _, w := range gs.Winners {
+		gs.recordEvent(Event{
+			Type:      EventAward,
+			PlayerIdx: w.PlayerIdx,
+			Amount:    w.Amount,
+			HandDesc:  w.HandDesc,
+			PotNumber: w.PotNumber,
+		})
+	}
+	gs.countLastHand()
+}
+
+// Replay builds a fresh GameState from config and an event log. The returned state
+// records its own copy of the events, so its log matches the input.
+func Replay(config GameConfig, events []Event) (*GameState, error) {
+	gs := NewGame(config)
+	for _, ev := range events {
+		if err := gs.applyEvent(ev); err != nil {
+			return nil, fmt.Errorf("replaying event %d (%s): %w", ev.Seq, ev.Type, err)
+		}
+	}
+	return gs, nil
+}
+
+func (gs *GameState) applyEvent(ev Event) error {
+	switch ev.Type {
+	case EventHandStart:
+		return gs.startHand(ev.HandNumber, ev.Button, ev.Deck)
+	case EventAction:
+		if ev.Action == nil {
+			return fmt.Errorf("action event has no action")
+		}
+		return gs.ProcessAction(*ev.Action)
+	case EventStreet:
+		return gs.AdvanceStreet()
+	}
+	return nil // post, deal and award events are re-derived by the cases above
+}
+
+// Undo reverts the most recent player action. Only allowed in test mode.
+func (gs *GameState) Undo() error {
+	for i := len(gs.Events) - 1; i >= 0; i-- {
+		if gs.Events[i].Type == EventAction {
+			return gs.RewindTo(gs.Events[i].Seq)
+		}
+	}
+	return fmt.Errorf("no action to undo")
+}
+
+// RewindTo restores the state from just before event seq, which must be an action or
+// hand start. Everything from seq onwards is dropped. Only allowed in test mode.
+func (gs *GameState) RewindTo(seq int) error {
+	if gs.Mode != ModeTest {
+		return fmt.Errorf("rewind is only available in test mode")
+	}
+	if seq < 0 || seq >= len(gs.Events) {
+		return fmt.Errorf("no event with sequence %d", seq)
+	}
+	if t := gs.Events[seq].Type; t != EventAction && t != EventHandStart {
+		return fmt.Errorf("can only rewind to an action or hand start, event %d is %s", seq, t)
+	}
+
+	rebuilt, err := Replay(gs.config, gs.Events[:seq])
+	if err != nil {
+		return err
+	}
+	rebuilt.ID = gs.ID
+	rebuilt.GameStartTime = gs.GameStartTime
+	if rebuilt.HandNumber == 0 {
+		rebuilt.ButtonIdx = gs.Events[seq].Button // Keep the button from DetermineButton
+	}
+	*gs = *rebuilt
+	return nil
+}
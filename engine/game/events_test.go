@@ -0,0 +1,104 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestRewindMatchesPlay plays two hands of a test mode game, snapshotting the state
+// before every hand start and action, then rewinds a copy of the finished game to each
+// of those events and compares it with the snapshot. Undo must match the snapshot
+// taken before the last action.
+func TestRewindMatchesPlay(t *testing.T) {
+	gs := NewGame(GameConfig{
+		PlayerNames:    []string{"A", "B", "C"},
+		StartingStacks: []int{1000, 600, 300},
+		Stakes:         Stakes{SmallBlind: 5, BigBlind: 10},
+		Mode:           ModeTest,
+	})
+	gs.DetermineButton()
+
+	snapshots := make(map[int]*GameState)
+	step := 0
+	for hand := 0; hand < 2; hand++ {
+		snapshots[len(gs.Events)] = gs.Clone()
+		if err := gs.StartHand(); err != nil {
+			t.Fatal(err)
+		}
+		for !gs.IsHandComplete() {
+			if gs.NeedToAdvanceStreet() {
+				if err := gs.AdvanceStreet(); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			// Raise the minimum every third decision, otherwise check or call
+			action := Action{Type: ActionCall, PlayerIdx: gs.CurrentPlayerIdx}
+			for _, va := range gs.GetValidActions() {
+				switch {
+				case va.Type == ActionRaise && step%3 == 0:
+					action.Type, action.Amount = ActionRaise, va.MinAmount
+				case va.Type == ActionCheck && action.Type != ActionRaise:
+					action.Type = ActionCheck
+				}
+			}
+			step++
+			snapshots[len(gs.Events)] = gs.Clone()
+			if err := gs.ProcessAction(action); err != nil {
+				t.Fatal(err)
+			}
+		}
+		gs.EliminateBrokePlayers()
+	}
+
+	type check struct {
+		field     string
+		got, want any
+	}
+	compare := func(what string, got, want *GameState) {
+		t.Helper()
+		checks := []check{
+			{"events", len(got.Events), len(want.Events)},
+			{"hand", got.HandNumber, want.HandNumber},
+			{"button", got.ButtonIdx, want.ButtonIdx},
+			{"street", got.Street, want.Street},
+			{"players", got.Players, want.Players},
+			{"pots", got.Pots, want.Pots},
+			{"board", got.CommunityCards, want.CommunityCards},
+			{"current player", got.CurrentPlayerIdx, want.CurrentPlayerIdx},
+			{"current bet", got.CurrentBet, want.CurrentBet},
+			{"min raise", got.MinRaise, want.MinRaise},
+		}
+		// Before the first hand the deck is an unused shuffle
+		if want.HandNumber > 0 {
+			checks = append(checks,
+				check{"deck", got.deck.cards, want.deck.cards},
+				check{"deck position", got.deck.index, want.deck.index})
+		}
+		for _, c := range checks {
+			if !reflect.DeepEqual(c.got, c.want) {
+				t.Errorf("%s, %s = %+v, want %+v", what, c.field, c.got, c.want)
+			}
+		}
+	}
+
+	for seq, want := range snapshots {
+		got := gs.Clone()
+		if err := got.RewindTo(seq); err != nil {
+			t.Fatalf("rewind to %d: %v", seq, err)
+		}
+		compare(fmt.Sprintf("rewound to %d", seq), got, want)
+	}
+
+	// Undo takes back the last action, which completed the second hand
+	last := len(gs.Events) - 1
+	for gs.Events[last].Type != EventAction {
+		last--
+	}
+	got := gs.Clone()
+	if err := got.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	compare("undone", got, snapshots[last])
+}
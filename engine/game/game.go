@@ -1,7 +1,8 @@
 // This file is the heart of the poker engine. It holds the GameState struct which tracks
 // everything: players, cards, pots, betting state. NewGame creates a table, StartHand deals
 // cards and posts blinds, AdvanceStreet moves through flop/turn/river, and resolveShowdown
-// determines winners. Called by api/game_handlers.go and api/llm_handlers.go.
+// determines winners. Every transition is also appended to Events (events.go). Called by
+// api/game_handlers.go and api/llm_handlers.go.
 package game
 
 import (
@@ -42,11 +43,14 @@ type Stakes struct {
 }
 
 type GameConfig struct {
-	PlayerNames   []string `json:"playerNames"`
-	StartingStack int      `json:"startingStack"`
-	Stakes        Stakes   `json:"stakes"`
-	Mode          GameMode `json:"mode"`
-	UserSeatIdx   int      `json:"userSeatIdx"` // Only relevant in ModePlay
+	PlayerNames    []string     `json:"playerNames"`
+	StartingStack  int          `json:"startingStack"`
+	StartingStacks []int        `json:"startingStacks,omitempty"` // Per-player override of StartingStack
+	Stakes         Stakes       `json:"stakes"`
+	Mode           GameMode     `json:"mode"`
+	UserSeatIdx    int          `json:"userSeatIdx"`        // Only relevant in ModePlay
+	UserName       string       `json:"userName,omitempty"` // Tags the user's decisions in ModePlay datasets
+	Prompt         PromptConfig `json:"prompt"`             // Optional LLM prompt sections
 }
 
 type GameState struct {
@@ -63,12 +67,16 @@ type GameState struct {
 	Stakes             Stakes            `json:"stakes"`
 	Mode               GameMode          `json:"mode"`
 	UserSeatIdx        int               `json:"userSeatIdx"`
+	UserName           string            `json:"userName,omitempty"`
 	HandNumber         int               `json:"handNumber"`
 	Winners            []Winner          `json:"winners,omitempty"`
 	GameStartTime      time.Time         `json:"gameStartTime"`
+	Events             []Event           `json:"-"` // Append-only log, see events.go
+	config             GameConfig        `json:"-"`
+	statCounts         []StatCounts      `json:"-"` // Per seat, updated as each hand completes (stats.go)
 	deck               *Deck             `json:"-"`
 	actionsThisRound   int               `json:"-"`
-	LLMActionsThisHand []map[string]any  `json:"-"`
+	LLMActionsThisHand []LLMAction       `json:"-"`
 	LLMPreviousHands   []LLMPreviousHand `json:"-"`
 }
 
@@ -77,10 +85,14 @@ type WinnerFinder func(players []Player, communityCards []Card, eligibleIndices
 func NewGame(config GameConfig) *GameState {
 	players := make([]Player, len(config.PlayerNames))
 	for i, name := range config.PlayerNames {
+		stack := config.StartingStack
+		if len(config.StartingStacks) == len(config.PlayerNames) {
+			stack = config.StartingStacks[i]
+		}
 		players[i] = Player{
 			ID:           fmt.Sprintf("%d", i+1),
 			Name:         name,
-			Stack:        config.StartingStack,
+			Stack:        stack,
 			HoleCards:    []Card{},
 			Status:       PlayerActive,
 			SeatPosition: i,
@@ -98,10 +110,13 @@ func NewGame(config GameConfig) *GameState {
 		Stakes:             config.Stakes,
 		Mode:               config.Mode,
 		UserSeatIdx:        config.UserSeatIdx,
+		UserName:           config.UserName,
 		HandNumber:         0,
 		GameStartTime:      time.Now(), // Server timestamp for game start
+		Events:             []Event{},
+		config:             config,
 		deck:               NewDeck(),
-		LLMActionsThisHand: []map[string]any{},
+		LLMActionsThisHand: []LLMAction{},
 		LLMPreviousHands:   []LLMPreviousHand{},
 	}
 
@@ -150,6 +165,19 @@ func (gs *GameState) GetButtonWinner() int {
 }
 
 func (gs *GameState) StartHand() error {
+	// Only rotate button after the first hand (first hand uses button from DetermineButton)
+	button := gs.ButtonIdx
+	if gs.HandNumber > 0 {
+		button = gs.nextButton()
+	}
+
+	gs.deck.Reset()
+	return gs.startHand(gs.HandNumber+1, button, gs.deck.Order())
+}
+
+// startHand deals hand number handNumber with the given button and deck order.
+// StartHand picks these for live play; Replay takes them from an EventHandStart.
+func (gs *GameState) startHand(handNumber, button int, deck []Card) error {
 	gs.EliminateBrokePlayers()
 
 	activeCount := 0
@@ -162,8 +190,9 @@ func (gs *GameState) StartHand() error {
 		return fmt.Errorf("not enough players to start hand (need at least 2, have %d)", activeCount)
 	}
 
-	gs.HandNumber++
-	gs.deck.Reset()
+	gs.HandNumber = handNumber
+	gs.ButtonIdx = button
+	gs.deck.Stack(deck)
 	gs.CommunityCards = []Card{}
 	gs.Winners = nil
 	gs.ResetPotsForNewHand()
@@ -177,10 +206,7 @@ func (gs *GameState) StartHand() error {
 		}
 	}
 
-	// Only rotate button after the first hand (first hand uses button from DetermineButton)
-	if gs.HandNumber > 1 {
-		gs.rotateButton()
-	}
+	gs.recordHandStart(deck)
 	if err := gs.postBlinds(); err != nil {
 		return err
 	}
@@ -192,14 +218,14 @@ func (gs *GameState) StartHand() error {
 	return nil
 }
 
-func (gs *GameState) rotateButton() {
+func (gs *GameState) nextButton() int {
 	for i := 1; i <= len(gs.Players); i++ {
 		idx := (gs.ButtonIdx + i) % len(gs.Players)
 		if gs.Players[idx].Status != PlayerEliminated && gs.Players[idx].Stack > 0 {
-			gs.ButtonIdx = idx
-			return
+			return idx
 		}
 	}
+	return gs.ButtonIdx
 }
 
 func (gs *GameState) postBlinds() error {
@@ -228,8 +254,10 @@ func (gs *GameState) postBlinds() error {
 	gs.MinRaise = gs.Stakes.BigBlind
 	gs.LastRaiseAmount = gs.Stakes.BigBlind
 
-	gs.RecordActionForLLMs(sbPlayer.Name, "post", sbAmount)
-	gs.RecordActionForLLMs(bbPlayer.Name, "post", bbAmount)
+	gs.RecordActionForLLMs(sbIdx, "post", sbAmount, 0, sbPlayer.Stack+sbAmount)
+	gs.RecordActionForLLMs(bbIdx, "post", bbAmount, sbAmount, bbPlayer.Stack+bbAmount)
+	gs.recordEvent(Event{Type: EventPost, PlayerIdx: sbIdx, Amount: sbAmount})
+	gs.recordEvent(Event{Type: EventPost, PlayerIdx: bbIdx, Amount: bbAmount})
 
 	return nil
 }
@@ -263,9 +291,23 @@ func (gs *GameState) dealHoleCards() {
 			gs.Players[idx].HoleCards = append(gs.Players[idx].HoleCards, gs.deck.Deal(1)[0])
 		}
 	}
+	for i := range gs.Players {
+		if gs.Players[i].Status != PlayerEliminated {
+			gs.recordEvent(Event{Type: EventDeal, PlayerIdx: i, Cards: append([]Card{}, gs.Players[i].HoleCards...)})
+		}
+	}
 }
 
 func (gs *GameState) AdvanceStreet() error {
+	boardBefore := len(gs.CommunityCards)
+	if err := gs.advanceStreet(); err != nil {
+		return err
+	}
+	gs.recordStreet(gs.CommunityCards[boardBefore:])
+	return nil
+}
+
+func (gs *GameState) advanceStreet() error {
 	gs.CollectBetsIntoPot()
 
 	if gs.CountActivePlayers() == 1 {
@@ -315,9 +357,13 @@ func (gs *GameState) dealRiver() {
 }
 
 func (gs *GameState) runOutBoard() error {
+	// Deal street by street so burns fall where they would in live play
 	for len(gs.CommunityCards) < 5 {
-		gs.deck.Burn()
-		gs.CommunityCards = append(gs.CommunityCards, gs.deck.Deal(1)[0])
+		if len(gs.CommunityCards) == 0 {
+			gs.dealFlop()
+		} else {
+			gs.dealTurn()
+		}
 	}
 	gs.Street = StreetShowdown
 	return gs.resolveShowdown()
@@ -381,36 +427,65 @@ func (gs *GameState) EliminateBrokePlayers() {
 	}
 }
 
-func (gs *GameState) RecordActionForLLMs(playerName, action string, amount int) {
-	a := map[string]any{"player": playerName, "action": action}
-	if amount > 0 {
-		a["amount"] = amount
+// CountPlayersWithChips counts players who can be dealt into another hand. Below 2 the
+// game is over.
+func (gs *GameState) CountPlayersWithChips() int {
+	count := 0
+	for _, p := range gs.Players {
+		if p.Status != PlayerEliminated && p.Stack > 0 {
+			count++
+		}
 	}
-	gs.LLMActionsThisHand = append(gs.LLMActionsThisHand, a)
+	return count
+}
+
+// RecordActionForLLMs appends an action to the current hand's LLM history. potBefore and
+// stackBefore are the pot and the player's stack just before the action.
+func (gs *GameState) RecordActionForLLMs(playerIdx int, action string, amount, potBefore, stackBefore int) {
+	gs.LLMActionsThisHand = append(gs.LLMActionsThisHand, LLMAction{
+		Player:      gs.Players[playerIdx].Name,
+		Action:      action,
+		Amount:      amount,
+		Street:      gs.Street.String(),
+		Seat:        gs.Players[playerIdx].SeatPosition,
+		Position:    gs.getPositionName(playerIdx),
+		PotBefore:   potBefore,
+		StackBefore: stackBefore,
+	})
 }
 
 func (gs *GameState) ArchiveHandForLLMs() {
-	showdown := []map[string]any{}
-	if gs.Street == StreetShowdown || gs.Street == StreetComplete {
-		for i, p := range gs.Players {
-			if p.Status == PlayerActive || p.Status == PlayerAllIn {
-				showdown = append(showdown, map[string]any{
-					"player": p.Name,
-					"cards":  gs.GetLLMHoleCards(i),
-				})
-			}
+	var remaining []int
+	for i, p := range gs.Players {
+		if p.Status == PlayerActive || p.Status == PlayerAllIn {
+			remaining = append(remaining, i)
+		}
+	}
+
+	// Cards are only shown when the hand went to showdown, not when everyone else folded
+	showdown := []LLMShowdown{}
+	if len(remaining) > 1 {
+		for _, i := range remaining {
+			showdown = append(showdown, LLMShowdown{
+				Player: gs.Players[i].Name,
+				Seat:   gs.Players[i].SeatPosition,
+				Cards:  gs.GetLLMHoleCards(i),
+			})
 		}
 	}
 
-	winners := make([]map[string]any, len(gs.Winners))
+	winners := make([]LLMWinner, len(gs.Winners))
 	for i, w := range gs.Winners {
-		winners[i] = map[string]any{
-			"player": gs.Players[w.PlayerIdx].Name,
-			"amount": w.Amount,
+		winners[i] = LLMWinner{
+			Player:   gs.Players[w.PlayerIdx].Name,
+			Seat:     gs.Players[w.PlayerIdx].SeatPosition,
+			Amount:   w.Amount,
+			HandDesc: w.HandDesc,
 		}
 	}
 
 	hand := LLMPreviousHand{
+		HandNumber:     gs.HandNumber,
 		Players:        gs.GetLLMPlayers(),
 		CommunityCards: gs.GetLLMCommunityCards(),
 		Actions:        gs.LLMActionsThisHand,
@@ -418,7 +493,7 @@ func (gs *GameState) ArchiveHandForLLMs() {
 		Winners:        winners,
 	}
 	gs.LLMPreviousHands = append(gs.LLMPreviousHands, hand)
-	gs.LLMActionsThisHand = []map[string]any{}
+	gs.LLMActionsThisHand = []LLMAction{}
 }
 
 func (gs *GameState) GetLLMPlayers() []LLMPlayer {
@@ -496,7 +571,7 @@ func (gs *GameState) GetLLMPromptPayload(playerName string, validActions []LLMVa
 		return nil
 	}
 
-	return &LLMPromptPayload{
+	payload := &LLMPromptPayload{
 		YourName:        playerName,
 		YourCards:       gs.GetLLMHoleCards(playerIdx),
 		Players:         gs.GetLLMPlayers(),
@@ -506,6 +581,20 @@ func (gs *GameState) GetLLMPromptPayload(playerName string, validActions []LLMVa
 		PreviousHands:   gs.LLMPreviousHands,
 		ValidActions:    validActions,
 	}
+
+	payload.Context = gs.GetLLMDecisionContext(playerIdx, gs.config.Prompt.Context)
+
+	hud := gs.config.Prompt.HUD
+	if hud.Enabled {
+		payload.OpponentStats = gs.GetLLMOpponentStats(playerIdx, hud)
+	}
+	if hud.Enabled && hud.HideHistory {
+		payload.PreviousHands = []LLMPreviousHand{}
+		payload.EstimatedTokens = EstimateTokens(payload)
+	} else {
+		gs.applyHistory(payload, gs.config.Prompt.History)
+	}
+	return payload
 }
 
 func generateGameID() string {
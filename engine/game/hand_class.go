@@ -0,0 +1,20 @@
+// This file names starting hands by class, the way preflop charts do: "AA" for a pair,
+// "AKs" for suited and "AKo" for offsuit cards, higher rank first. Used by the bots in
+// decider/ to look hands up in their charts.
+package game
+
+// HandClass returns the class of two hole cards, e.g. "QQ", "T9s", "A5o".
+func HandClass(a, b Card) string {
+	if a.Rank < b.Rank {
+		a, b = b, a
+	}
+	class := a.Rank.String() + b.Rank.String()
+	switch {
+	case a.Rank == b.Rank:
+		return class
+	case a.Suit == b.Suit:
+		return class + "s"
+	default:
+		return class + "o"
+	}
+}
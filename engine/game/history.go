@@ -0,0 +1,94 @@
+// This file groups the event log (events.go) into per-hand records. CompletedHands is the
+// input for the hand history exporters in history/.
+package game
+
+import "slices"
+
+// HandRecord is one hand as recorded in the event log, from its hand_start event up to
+// and including its awards.
+type HandRecord struct {
+	Number int     `json:"number"`
+	Button int     `json:"button"`
+	Stacks []int   `json:"stacks"` // Stacks before blinds, indexed by player
+	Events []Event `json:"events"`
+}
+
+// Complete reports whether the hand reached the end (its last street event is "complete").
+func (h HandRecord) Complete() bool {
+	for i := len(h.Events) - 1; i >= 0; i-- {
+		if h.Events[i].Type == EventStreet {
+			return h.Events[i].Street == StreetComplete
+		}
+	}
+	return false
+}
+
+// HoleCards returns each player's hole cards from the deal events (nil if not dealt in).
+func (h HandRecord) HoleCards(numPlayers int) [][]Card {
+	cards := make([][]Card, numPlayers)
+	for _, ev := range h.Events {
+		if ev.Type == EventDeal && ev.PlayerIdx >= 0 && ev.PlayerIdx < numPlayers {
+			cards[ev.PlayerIdx] = ev.Cards
+		}
+	}
+	return cards
+}
+
+// Board returns the community cards dealt during the hand.
+func (h HandRecord) Board() []Card {
+	var board []Card
+	for _, ev := range h.Events {
+		if ev.Type == EventStreet {
+			board = append(board, ev.Cards...)
+		}
+	}
+	return board
+}
+
+// Hands splits the event log into hand records, including a hand still in progress.
+func (gs *GameState) Hands() []HandRecord {
+	var hands []HandRecord
+	for _, ev := range gs.Events {
+		if ev.Type == EventHandStart {
+			hands = append(hands, HandRecord{
+				Number: ev.HandNumber,
+				Button: ev.Button,
+				Stacks: ev.Stacks,
+			})
+		}
+		if len(hands) == 0 {
+			continue
+		}
+		last := &hands[len(hands)-1]
+		last.Events = append(last.Events, ev)
+	}
+	return hands
+}
+
+// LastHand returns the record of the latest hand without splitting the whole log, or
+// false before the first hand. Its events share the log's backing array, which is only
+// ever appended to, so the record can be read while the game goes on.
+func (gs *GameState) LastHand() (HandRecord, bool) {
+	for i := len(gs.Events) - 1; i >= 0; i-- {
+		if ev := gs.Events[i]; ev.Type == EventHandStart {
+			return HandRecord{
+				Number: ev.HandNumber,
+				Button: ev.Button,
+				Stacks: ev.Stacks,
+				Events: slices.Clip(gs.Events[i:]),
+			}, true
+		}
+	}
+	return HandRecord{}, false
+}
+
+// CompletedHands returns the hand records of every finished hand.
+func (gs *GameState) CompletedHands() []HandRecord {
+	var done []HandRecord
+	for _, h := range gs.Hands() {
+		if h.Complete() {
+			done = append(done, h)
+		}
+	}
+	return done
+}
@@ -0,0 +1,83 @@
+// This file builds the opponent HUD section of the LLM prompt: per opponent VPIP, PFR,
+// aggression factor and sample size from the session so far (stats.go), the summarized
+// reads a human would get from a HUD instead of the raw previous hands.
+package game
+
+// HUD stat names accepted in HUDConfig.Stats
+const (
+	HUDStatVPIP     = "vpip"
+	HUDStatPFR      = "pfr"
+	HUDStatAF       = "af"
+	HUDStat3Bet     = "threeBet"
+	HUDStatFoldTo3B = "foldToThreeBet"
+	HUDStatCBet     = "cBet"
+	HUDStatWTSD     = "wtsd"
+	HUDStatWSD      = "wsd"
+)
+
+var defaultHUDStats = []string{HUDStatVPIP, HUDStatPFR, HUDStatAF}
+
+type HUDConfig struct {
+	Enabled     bool     `json:"enabled"`
+	Stats       []string `json:"stats,omitempty"`       // Defaults to vpip, pfr, af
+	MinHands    int      `json:"minHands,omitempty"`    // Leave out opponents with a smaller sample
+	HideHistory bool     `json:"hideHistory,omitempty"` // Send the HUD instead of previousHands
+}
+
+// LLMOpponentStats is one opponent's HUD line. Only the configured stats are set.
+type LLMOpponentStats struct {
+	Name           string   `json:"name"`
+	Hands          int      `json:"hands"` // Sample size
+	VPIP           *float64 `json:"vpip,omitempty"`
+	PFR            *float64 `json:"pfr,omitempty"`
+	AF             *float64 `json:"af,omitempty"`
+	ThreeBet       *float64 `json:"threeBet,omitempty"`
+	FoldToThreeBet *float64 `json:"foldToThreeBet,omitempty"`
+	CBet           *float64 `json:"cBet,omitempty"`
+	WTSD           *float64 `json:"wtsd,omitempty"`
+	WSD            *float64 `json:"wsd,omitempty"`
+}
+
+// GetLLMOpponentStats returns HUD lines for everyone still in the game except playerIdx.
+func (gs *GameState) GetLLMOpponentStats(playerIdx int, cfg HUDConfig) []LLMOpponentStats {
+	names := cfg.Stats
+	if len(names) == 0 {
+		names = defaultHUDStats
+	}
+
+	hud := []LLMOpponentStats{}
+	for i, st := range gs.Stats() {
+		if i == playerIdx || gs.Players[i].Status == PlayerEliminated || st.Counts.Hands < cfg.MinHands {
+			continue
+		}
+		line := LLMOpponentStats{Name: st.Name, Hands: st.Counts.Hands}
+		for _, name := range names {
+			switch name {
+			case HUDStatVPIP:
+				line.VPIP = roundStat(st.VPIP)
+			case HUDStatPFR:
+				line.PFR = roundStat(st.PFR)
+			case HUDStatAF:
+				line.AF = roundStat(st.AF)
+			case HUDStat3Bet:
+				line.ThreeBet = roundStat(st.ThreeBet)
+			case HUDStatFoldTo3B:
+				line.FoldToThreeBet = roundStat(st.FoldToThreeBet)
+			case HUDStatCBet:
+				line.CBet = roundStat(st.CBet)
+			case HUDStatWTSD:
+				line.WTSD = roundStat(st.WTSD)
+			case HUDStatWSD:
+				line.WSD = roundStat(st.WSD)
+			}
+		}
+		hud = append(hud, line)
+	}
+	return hud
+}
+
+// roundStat keeps one decimal; more precision only costs prompt tokens.
+func roundStat(v float64) *float64 {
+	r := roundTo(v, 1)
+	return &r
+}
@@ -1,7 +1,8 @@
 // This file defines the JSON structures sent to LLMs when asking for their next action.
 // LLMPromptPayload contains everything an LLM needs: their cards, valid actions, pot size,
 // all previous actions this hand, and full history of previous hands. game.go builds these
-// payloads, and api/llm_handlers.go sends them to the Python LLM service.
+// payloads, and api/llm_handlers.go sends them to the Python LLM service. PromptConfig
+// holds the optional sections a game can switch on, such as the opponent HUD (hud.go).
 package game
 
 type LLMPlayer struct {
@@ -19,22 +20,62 @@ type LLMValidAction struct {
 	Description string `json:"description,omitempty"`
 }
 
+// LLMAction is one entry of a hand's action history, as it stood when the action was
+// taken. The player, action and amount keys are the ones the Python service always had.
+type LLMAction struct {
+	Player      string `json:"player"`
+	Action      string `json:"action"`
+	Amount      int    `json:"amount,omitempty"` // Blind posted, chips called, or bet/raise-to total
+	Street      string `json:"street"`
+	Seat        int    `json:"seat"`
+	Position    string `json:"position"`
+	PotBefore   int    `json:"potBefore"`
+	StackBefore int    `json:"stackBefore"`
+}
+
+type LLMShowdown struct {
+	Player string   `json:"player"`
+	Seat   int      `json:"seat"`
+	Cards  []string `json:"cards"`
+}
+
+type LLMWinner struct {
+	Player   string `json:"player"`
+	Seat     int    `json:"seat"`
+	Amount   int    `json:"amount"`
+	HandDesc string `json:"handDesc,omitempty"` // Empty when everyone else folded
+}
+
 // Shared across all LLMs - they see the same hand history
 type LLMPreviousHand struct {
-	Players        []LLMPlayer      `json:"players"`
-	CommunityCards []string         `json:"communityCards"`
-	Actions        []map[string]any `json:"actions"`
-	Showdown       []map[string]any `json:"showdown"`
-	Winners        []map[string]any `json:"winners"`
+	HandNumber     int           `json:"handNumber"`
+	Players        []LLMPlayer   `json:"players"`
+	CommunityCards []string      `json:"communityCards"`
+	Actions        []LLMAction   `json:"actions"`
+	Showdown       []LLMShowdown `json:"showdown"`
+	Winners        []LLMWinner   `json:"winners"`
 }
 
 type LLMPromptPayload struct {
-	YourName        string            `json:"yourName"`
-	YourCards       []string          `json:"yourCards"`
-	Players         []LLMPlayer       `json:"players"`
-	CommunityCards  []string          `json:"communityCards"`
-	Pot             int               `json:"pot"`
-	ActionsThisHand []map[string]any  `json:"actionsThisHand"`
-	PreviousHands   []LLMPreviousHand `json:"previousHands"`
-	ValidActions    []LLMValidAction  `json:"validActions"`
+	YourName             string              `json:"yourName"`
+	YourCards            []string            `json:"yourCards"`
+	Players              []LLMPlayer         `json:"players"`
+	CommunityCards       []string            `json:"communityCards"`
+	Pot                  int                 `json:"pot"`
+	ActionsThisHand      []LLMAction         `json:"actionsThisHand"`
+	PreviousHands        []LLMPreviousHand   `json:"previousHands"`
+	PreviousHandsCompact []string            `json:"previousHandsCompact,omitempty"` // Only with the compact history strategy
+	HistorySummary       string              `json:"historySummary,omitempty"`       // Only with the summary history strategy
+	OpponentStats        []LLMOpponentStats  `json:"opponentStats,omitempty"`        // Only with PromptConfig.HUD enabled
+	Context              *LLMDecisionContext `json:"context,omitempty"`              // Only with PromptConfig.Context fields enabled
+	ValidActions         []LLMValidAction    `json:"validActions"`
+
+	EstimatedTokens int `json:"-"` // Reported to the UI and datasets, not sent to the model
+}
+
+// PromptConfig selects optional prompt sections. The zero value is the original prompt.
+type PromptConfig struct {
+	HUD     HUDConfig     `json:"hud"`
+	History HistoryConfig `json:"history"` // See prompt_history.go
+	Context ContextConfig `json:"context"` // See decision_context.go
 }
@@ -177,9 +177,10 @@ func (gs *GameState) AwardPots(communityCards []Card, evaluateFunc func([]Player
 		eligible := make([]int, len(pot.EligiblePlayers))
 		copy(eligible, pot.EligiblePlayers)
 
-		for i, winnerIdx := range potWinners {
+		oddChipIdx := gs.firstLeftOfButton(potWinners)
+		for _, winnerIdx := range potWinners {
 			amount := splitAmount
-			if i == 0 { // Remainder to first winner
+			if winnerIdx == oddChipIdx { // Remainder to first winner left of the button
 				amount += remainder
 			}
 
@@ -252,3 +253,17 @@ func (gs *GameState) ResetPotsForNewHand() {
 		gs.Players[i].CurrentBet = 0
 	}
 }
+
+// firstLeftOfButton returns the player in players seated closest to the button's left,
+// who receives the odd chips of a split pot.
+func (gs *GameState) firstLeftOfButton(players []int) int {
+	n := len(gs.Players)
+	best, bestDist := -1, n+1
+	for _, idx := range players {
+		dist := (idx - gs.ButtonIdx - 1 + n) % n
+		if dist < bestDist {
+			best, bestDist = idx, dist
+		}
+	}
+	return best
+}
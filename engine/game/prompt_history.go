@@ -0,0 +1,195 @@
+// This file applies PromptConfig.History to the previous hands sent to an LLM. The full
+// history grows every hand and eventually overflows context windows, so a game can keep
+// the last N hands, only hands the acting player was in, one compact line per hand, or
+// the last N hands plus a rolling summary of the rest, optionally under a token budget.
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// History strategies for HistoryConfig.Strategy
+const (
+	HistoryFull     = "full"     // Every previous hand (default)
+	HistoryLastN    = "last_n"   // The last LastN hands
+	HistoryInvolved = "involved" // Only hands the acting player was dealt into and acted in
+	HistoryCompact  = "compact"  // Every hand as one line in previousHandsCompact
+	HistorySummary  = "summary"  // The last LastN hands plus historySummary for the rest
+)
+
+const defaultHistoryLastN = 10
+
+type HistoryConfig struct {
+	Strategy  string `json:"strategy,omitempty"`
+	LastN     int    `json:"lastN,omitempty"`     // Hands kept in full by last_n and summary (default 10)
+	MaxTokens int    `json:"maxTokens,omitempty"` // Drop the oldest hands until the prompt fits
+}
+
+// EstimateTokens approximates a payload's prompt size the way the LLM service does
+// (about 4 characters of JSON per token).
+func EstimateTokens(payload *LLMPromptPayload) int {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0
+	}
+	return len(data) / 4
+}
+
+// applyHistory replaces payload.PreviousHands according to cfg and sets
+// payload.EstimatedTokens.
+func (gs *GameState) applyHistory(payload *LLMPromptPayload, cfg HistoryConfig) {
+	hands := gs.LLMPreviousHands
+	lastN := cfg.LastN
+	if lastN <= 0 {
+		lastN = defaultHistoryLastN
+	}
+
+	summarized := 0 // Hands folded into the summary, oldest first
+	switch cfg.Strategy {
+	case HistoryLastN:
+		hands = hands[max(0, len(hands)-lastN):]
+	case HistoryInvolved:
+		var involved []LLMPreviousHand
+		for _, h := range hands {
+			if handInvolves(h, payload.YourName) {
+				involved = append(involved, h)
+			}
+		}
+		hands = involved
+	case HistoryCompact:
+		payload.PreviousHandsCompact = make([]string, len(hands))
+		for i, h := range hands {
+			payload.PreviousHandsCompact[i] = compactHand(h)
+		}
+		hands = nil
+	case HistorySummary:
+		summarized = max(0, len(hands)-lastN)
+		hands = hands[summarized:]
+	}
+	payload.PreviousHands = append([]LLMPreviousHand{}, hands...)
+	payload.HistorySummary = gs.summarizeHands(summarized)
+
+	if cfg.MaxTokens > 0 {
+		gs.fitHistory(payload, cfg, summarized)
+	}
+	payload.EstimatedTokens = EstimateTokens(payload)
+}
+
+// fitHistory drops the oldest hands until the payload's estimate is within the budget.
+// Sizes are measured once per hand rather than re-encoding the payload for every drop.
+func (gs *GameState) fitHistory(payload *LLMPromptPayload, cfg HistoryConfig, summarized int) {
+	total := EstimateTokens(payload)
+	for total > cfg.MaxTokens {
+		switch {
+		case len(payload.PreviousHands) > 0:
+			total -= jsonTokens(payload.PreviousHands[0])
+			payload.PreviousHands = payload.PreviousHands[1:]
+			if cfg.Strategy == HistorySummary {
+				summarized++
+				total -= len(payload.HistorySummary) / 4
+				payload.HistorySummary = gs.summarizeHands(summarized)
+				total += len(payload.HistorySummary) / 4
+			}
+		case len(payload.PreviousHandsCompact) > 0:
+			total -= jsonTokens(payload.PreviousHandsCompact[0])
+			payload.PreviousHandsCompact = payload.PreviousHandsCompact[1:]
+		default:
+			return // Nothing left to drop
+		}
+	}
+}
+
+// jsonTokens estimates one history entry, including the comma separating it.
+func jsonTokens(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + 1) / 4
+}
+
+func handInvolves(h LLMPreviousHand, name string) bool {
+	for _, a := range h.Actions {
+		if a.Player == name && a.Action != "post" {
+			return true
+		}
+	}
+	return false
+}
+
+// compactHand renders a hand as one line, e.g.
+// "#12 A:BTN B:SB C:BB | Preflop: B post 5, C post 10, A RAISE 30, B FOLD, C CALL | Flop: C CHECK, A CHECK | board Ah Kd 2c | C shows Qs Qd | C wins 65"
+func compactHand(h LLMPreviousHand) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d", h.HandNumber)
+	for _, p := range h.Players {
+		fmt.Fprintf(&b, " %s:%s", p.Name, p.Position)
+	}
+
+	street := ""
+	for _, a := range h.Actions {
+		if a.Street != street {
+			street = a.Street
+			fmt.Fprintf(&b, " | %s: ", street)
+		} else {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s %s", a.Player, a.Action)
+		if a.Amount > 0 {
+			fmt.Fprintf(&b, " %d", a.Amount)
+		}
+	}
+
+	if len(h.CommunityCards) > 0 {
+		b.WriteString(" | board " + strings.Join(h.CommunityCards, " "))
+	}
+	for _, s := range h.Showdown {
+		fmt.Fprintf(&b, " | %s shows %s", s.Player, strings.Join(s.Cards, " "))
+	}
+	for _, w := range h.Winners {
+		fmt.Fprintf(&b, " | %s wins %d", w.Player, w.Amount)
+	}
+	return b.String()
+}
+
+// summarizeHands describes the first n previous hands: pots won and net chips per player.
+func (gs *GameState) summarizeHands(n int) string {
+	if n <= 0 || n > len(gs.LLMPreviousHands) {
+		return ""
+	}
+	hands := gs.LLMPreviousHands[:n]
+
+	potsWon := make(map[string]int)
+	biggest, biggestWinner, biggestHand := 0, "", 0
+	for _, h := range hands {
+		for _, w := range h.Winners {
+			potsWon[w.Player]++
+			if w.Amount > biggest {
+				biggest, biggestWinner, biggestHand = w.Amount, w.Player, h.HandNumber
+			}
+		}
+	}
+
+	// Players carry stacks as of the end of each hand
+	final := hands[len(hands)-1].Players
+	parts := make([]string, 0, len(final))
+	for i, p := range final {
+		net := p.Stack - gs.startingStack(i)
+		parts = append(parts, fmt.Sprintf("%s won %d pots, net %+d", p.Name, potsWon[p.Name], net))
+	}
+
+	summary := fmt.Sprintf("Hands %d-%d: %s", hands[0].HandNumber, hands[len(hands)-1].HandNumber, strings.Join(parts, "; "))
+	if biggestWinner != "" {
+		summary += fmt.Sprintf(". Largest win: %d by %s (hand %d)", biggest, biggestWinner, biggestHand)
+	}
+	return summary
+}
+
+func (gs *GameState) startingStack(playerIdx int) int {
+	if len(gs.config.StartingStacks) == len(gs.Players) {
+		return gs.config.StartingStacks[playerIdx]
+	}
+	return gs.config.StartingStack
+}
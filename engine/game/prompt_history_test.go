@@ -0,0 +1,149 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+// historyGame is a game of A, B and C with six previous hands. A raises in the odd
+// hands and takes the blinds; in the even hands A isn't dealt in and B takes C's big
+// blind.
+func historyGame() *GameState {
+	gs := NewGame(GameConfig{PlayerNames: []string{"A", "B", "C"}, StartingStack: 1000})
+	stacks := map[string]int{"A": 1000, "B": 1000, "C": 1000}
+	for n := 1; n <= 6; n++ {
+		h := LLMPreviousHand{
+			HandNumber: n,
+			Actions: []LLMAction{
+				{Player: "B", Action: "post", Amount: 5, Street: "preflop", Seat: 1},
+				{Player: "C", Action: "post", Amount: 10, Street: "preflop", Seat: 2},
+			},
+		}
+		if n%2 == 1 {
+			h.Actions = append(h.Actions,
+				LLMAction{Player: "A", Action: "RAISE", Amount: 30, Street: "preflop", Seat: 0},
+				LLMAction{Player: "B", Action: "FOLD", Street: "preflop", Seat: 1},
+				LLMAction{Player: "C", Action: "FOLD", Street: "preflop", Seat: 2})
+			h.Winners = []LLMWinner{{Player: "A", Seat: 0, Amount: 45}}
+			stacks["A"] += 15
+			stacks["B"] -= 5
+		} else {
+			h.Actions = append(h.Actions,
+				LLMAction{Player: "B", Action: "RAISE", Amount: 30, Street: "preflop", Seat: 1},
+				LLMAction{Player: "C", Action: "FOLD", Street: "preflop", Seat: 2})
+			h.Winners = []LLMWinner{{Player: "B", Seat: 1, Amount: 40}}
+			stacks["B"] += 10
+		}
+		stacks["C"] -= 10
+		for seat, name := range []string{"A", "B", "C"} {
+			h.Players = append(h.Players, LLMPlayer{Name: name, Seat: seat, Stack: stacks[name], Position: []string{"BTN", "SB", "BB"}[seat]})
+		}
+		gs.LLMPreviousHands = append(gs.LLMPreviousHands, h)
+	}
+	return gs
+}
+
+func handNumbers(hands []LLMPreviousHand) []int {
+	var numbers []int
+	for _, h := range hands {
+		numbers = append(numbers, h.HandNumber)
+	}
+	return numbers
+}
+
+func TestApplyHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     HistoryConfig
+		player  string
+		hands   []int
+		compact []string
+		summary string
+	}{
+		{name: "full", player: "A", hands: []int{1, 2, 3, 4, 5, 6}},
+		{name: "last n", cfg: HistoryConfig{Strategy: HistoryLastN, LastN: 2}, player: "A", hands: []int{5, 6}},
+		{name: "last n beyond the history", cfg: HistoryConfig{Strategy: HistoryLastN}, player: "A", hands: []int{1, 2, 3, 4, 5, 6}},
+		{name: "involved, posting doesn't count", cfg: HistoryConfig{Strategy: HistoryInvolved}, player: "A", hands: []int{1, 3, 5}},
+		{name: "involved in every hand", cfg: HistoryConfig{Strategy: HistoryInvolved}, player: "C", hands: []int{1, 2, 3, 4, 5, 6}},
+		{
+			name: "compact", cfg: HistoryConfig{Strategy: HistoryCompact}, player: "A",
+			compact: []string{
+				"#1 A:BTN B:SB C:BB | preflop: B post 5, C post 10, A RAISE 30, B FOLD, C FOLD | A wins 45",
+				"#2 A:BTN B:SB C:BB | preflop: B post 5, C post 10, B RAISE 30, C FOLD | B wins 40",
+				"#3 A:BTN B:SB C:BB | preflop: B post 5, C post 10, A RAISE 30, B FOLD, C FOLD | A wins 45",
+				"#4 A:BTN B:SB C:BB | preflop: B post 5, C post 10, B RAISE 30, C FOLD | B wins 40",
+				"#5 A:BTN B:SB C:BB | preflop: B post 5, C post 10, A RAISE 30, B FOLD, C FOLD | A wins 45",
+				"#6 A:BTN B:SB C:BB | preflop: B post 5, C post 10, B RAISE 30, C FOLD | B wins 40",
+			},
+		},
+		{
+			name: "summary", cfg: HistoryConfig{Strategy: HistorySummary, LastN: 2}, player: "A",
+			hands:   []int{5, 6},
+			summary: "Hands 1-4: A won 2 pots, net +30; B won 2 pots, net +10; C won 0 pots, net -40. Largest win: 45 by A (hand 1)",
+		},
+		{name: "summary of nothing", cfg: HistoryConfig{Strategy: HistorySummary, LastN: 6}, player: "A", hands: []int{1, 2, 3, 4, 5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := historyGame()
+			payload := &LLMPromptPayload{YourName: tt.player}
+			gs.applyHistory(payload, tt.cfg)
+			if got := handNumbers(payload.PreviousHands); !slices.Equal(got, tt.hands) {
+				t.Errorf("hands %v, want %v", got, tt.hands)
+			}
+			if !slices.Equal(payload.PreviousHandsCompact, tt.compact) {
+				t.Errorf("compact hands\n%q\nwant\n%q", payload.PreviousHandsCompact, tt.compact)
+			}
+			if payload.HistorySummary != tt.summary {
+				t.Errorf("summary %q, want %q", payload.HistorySummary, tt.summary)
+			}
+			if payload.EstimatedTokens != EstimateTokens(payload) {
+				t.Errorf("estimated %d tokens, the payload is %d", payload.EstimatedTokens, EstimateTokens(payload))
+			}
+		})
+	}
+}
+
+// TestApplyHistoryMaxTokens halves each strategy's budget and checks that the oldest
+// hands were dropped to fit it.
+func TestApplyHistoryMaxTokens(t *testing.T) {
+	for _, strategy := range []string{HistoryFull, HistoryLastN, HistoryCompact, HistorySummary} {
+		t.Run(strategy, func(t *testing.T) {
+			cfg := HistoryConfig{Strategy: strategy, LastN: 4}
+			full := &LLMPromptPayload{YourName: "A"}
+			historyGame().applyHistory(full, cfg)
+
+			cfg.MaxTokens = full.EstimatedTokens / 2
+			payload := &LLMPromptPayload{YourName: "A"}
+			historyGame().applyHistory(payload, cfg)
+			if payload.EstimatedTokens > cfg.MaxTokens {
+				t.Errorf("%d tokens, over the budget of %d", payload.EstimatedTokens, cfg.MaxTokens)
+			}
+
+			kept := handNumbers(payload.PreviousHands)
+			if strategy == HistoryCompact {
+				keptNewest(t, payload.PreviousHandsCompact, full.PreviousHandsCompact)
+			} else {
+				keptNewest(t, kept, handNumbers(full.PreviousHands))
+			}
+
+			if strategy == HistorySummary {
+				want := historyGame().summarizeHands(kept[0] - 1)
+				if payload.HistorySummary != want {
+					t.Errorf("summary %q, want the dropped hands summarized: %q", payload.HistorySummary, want)
+				}
+			}
+		})
+	}
+}
+
+// keptNewest checks that kept is some but not all of history, and its newest entries.
+func keptNewest[T comparable](t *testing.T, kept, history []T) {
+	t.Helper()
+	if len(kept) == 0 || len(kept) >= len(history) {
+		t.Fatalf("kept %v of %v, want some dropped and some kept", kept, history)
+	}
+	if !slices.Equal(kept, history[len(history)-len(kept):]) {
+		t.Errorf("kept %v, want the newest of %v", kept, history)
+	}
+}
@@ -0,0 +1,282 @@
+// This file computes per-player statistics (VPIP, PFR, 3-bet, fold to 3-bet, c-bet,
+// aggression factor, WTSD, W$SD, bb/100) from the event log. StatCounts holds the raw
+// counts so stats from several games can be merged before the percentages are derived.
+package game
+
+// StatCounts are the opportunities and occurrences behind each stat.
+type StatCounts struct {
+	Hands          int     `json:"hands"`          // Hands dealt in
+	VPIP           int     `json:"vpip"`           // Put money in preflop voluntarily
+	PFR            int     `json:"pfr"`            // Raised preflop
+	ThreeBetOpps   int     `json:"threeBetOpps"`   // Faced a single raise preflop
+	ThreeBets      int     `json:"threeBets"`      // Re-raised it
+	FoldTo3BetOpps int     `json:"foldTo3BetOpps"` // Opened and faced a 3-bet
+	FoldTo3Bets    int     `json:"foldTo3Bets"`    // Folded to it
+	CBetOpps       int     `json:"cBetOpps"`       // Preflop aggressor first to bet on the flop
+	CBets          int     `json:"cBets"`          // Bet the flop
+	PostflopAggr   int     `json:"postflopAggr"`   // Postflop bets and raises
+	PostflopCalls  int     `json:"postflopCalls"`  // Postflop calls
+	SawFlop        int     `json:"sawFlop"`        // Still in the hand when the flop came
+	WentToShowdown int     `json:"wentToShowdown"` // Saw the flop and reached showdown
+	WonAtShowdown  int     `json:"wonAtShowdown"`  // Won chips at showdown
+	NetChips       int     `json:"netChips"`       // Chips won minus chips put in
+	NetBigBlinds   float64 `json:"netBigBlinds"`   // NetChips in big blinds of the game played
+}
+
+// Add merges other into c.
+func (c *StatCounts) Add(other StatCounts) {
+	c.Hands += other.Hands
+	c.VPIP += other.VPIP
+	c.PFR += other.PFR
+	c.ThreeBetOpps += other.ThreeBetOpps
+	c.ThreeBets += other.ThreeBets
+	c.FoldTo3BetOpps += other.FoldTo3BetOpps
+	c.FoldTo3Bets += other.FoldTo3Bets
+	c.CBetOpps += other.CBetOpps
+	c.CBets += other.CBets
+	c.PostflopAggr += other.PostflopAggr
+	c.PostflopCalls += other.PostflopCalls
+	c.SawFlop += other.SawFlop
+	c.WentToShowdown += other.WentToShowdown
+	c.WonAtShowdown += other.WonAtShowdown
+	c.NetChips += other.NetChips
+	c.NetBigBlinds += other.NetBigBlinds
+}
+
+// PlayerStats are the derived stats. Percentages are 0-100; a stat with no
+// opportunities yet is 0.
+type PlayerStats struct {
+	Name           string     `json:"name"`
+	VPIP           float64    `json:"vpip"`
+	PFR            float64    `json:"pfr"`
+	ThreeBet       float64    `json:"threeBet"`
+	FoldToThreeBet float64    `json:"foldToThreeBet"`
+	CBet           float64    `json:"cBet"`
+	AF             float64    `json:"af"` // Postflop (bets + raises) / calls
+	WTSD           float64    `json:"wtsd"`
+	WSD            float64    `json:"wsd"` // W$SD
+	BBPer100       float64    `json:"bbPer100"`
+	Counts         StatCounts `json:"counts"`
+}
+
+func NewPlayerStats(name string, c StatCounts) PlayerStats {
+	af := float64(c.PostflopAggr)
+	if c.PostflopCalls > 0 {
+		af /= float64(c.PostflopCalls)
+	}
+	var bbPer100 float64
+	if c.Hands > 0 {
+		bbPer100 = c.NetBigBlinds * 100 / float64(c.Hands)
+	}
+	return PlayerStats{
+		Name:           name,
+		VPIP:           percent(c.VPIP, c.Hands),
+		PFR:            percent(c.PFR, c.Hands),
+		ThreeBet:       percent(c.ThreeBets, c.ThreeBetOpps),
+		FoldToThreeBet: percent(c.FoldTo3Bets, c.FoldTo3BetOpps),
+		CBet:           percent(c.CBets, c.CBetOpps),
+		AF:             af,
+		WTSD:           percent(c.WentToShowdown, c.SawFlop),
+		WSD:            percent(c.WonAtShowdown, c.WentToShowdown),
+		BBPer100:       bbPer100,
+		Counts:         c,
+	}
+}
+
+func percent(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(d)
+}
+
+// StatCounts returns each player's counts over the game's completed hands, indexed
+// like gs.Players.
+func (gs *GameState) StatCounts() []StatCounts {
+	counts := make([]StatCounts, len(gs.Players))
+	copy(counts, gs.statCounts)
+	return counts
+}
+
+// countLastHand adds the hand that just completed to gs.statCounts, so stats never
+// need the whole log replayed. Replay rebuilds them along with everything else.
+func (gs *GameState) countLastHand() {
+	if len(gs.statCounts) != len(gs.Players) {
+		gs.statCounts = make([]StatCounts, len(gs.Players))
+	}
+	hand, ok := gs.LastHand()
+	if !ok {
+		return
+	}
+
+	after := make([]int, len(gs.Players))
+	for i, p := range gs.Players {
+		after[i] = p.Stack
+	}
+	countHand(gs.statCounts, hand, after, gs.Stakes.BigBlind)
+}
+
+// Stats returns each player's stats for this game, indexed like gs.Players.
+func (gs *GameState) Stats() []PlayerStats {
+	counts := gs.StatCounts()
+	stats := make([]PlayerStats, len(gs.Players))
+	for i, p := range gs.Players {
+		stats[i] = NewPlayerStats(p.Name, counts[i])
+	}
+	return stats
+}
+
+// countHand adds one completed hand to counts, given each player's stack after it.
+func countHand(counts []StatCounts, hand HandRecord, after []int, bigBlind int) {
+	n := len(counts)
+	dealt := make([]bool, n)
+	folded := make([]bool, n)
+	foldedPreflop := make([]bool, n)
+	won := make([]bool, n)
+	vpip := make([]bool, n)
+	pfr := make([]bool, n)
+	streetBet := make([]int, n) // Chips put in on the current street
+
+	var (
+		street         = StreetPreflop
+		maxBet         int
+		raises         int // Preflop raises so far, the big blind not counted
+		opener         = -1
+		lastAggressor  = -1 // Last preflop raiser
+		flopBet        bool
+		reachedFlop    bool
+		boardCards     int
+		seen3BetOpp    = make([]bool, n)
+		seenFoldTo3Bet = make([]bool, n)
+		seenCBetOpp    bool
+	)
+
+	for _, ev := range hand.Events {
+		if ev.PlayerIdx >= n {
+			continue
+		}
+		switch ev.Type {
+		case EventDeal:
+			dealt[ev.PlayerIdx] = true
+
+		case EventPost:
+			// Calling a short big blind still takes the full big blind, and isn't a raise
+			streetBet[ev.PlayerIdx] += ev.Amount
+			maxBet = bigBlind
+
+		case EventStreet:
+			// An all-in runout deals several streets in one event
+			boardCards += len(ev.Cards)
+			reachedFlop = boardCards >= 3
+			street = ev.Street
+			maxBet = 0
+			for i := range streetBet {
+				streetBet[i] = 0
+			}
+
+		case EventAward:
+			won[ev.PlayerIdx] = true
+
+		case EventAction:
+			p := ev.PlayerIdx
+			c := &counts[p]
+			streetBet[p] += ev.Amount
+
+			fold := ev.Resolved != nil && ev.Resolved.Type == ActionFold
+			aggressive := streetBet[p] > maxBet
+			call := !aggressive && ev.Amount > 0
+			if aggressive {
+				maxBet = streetBet[p]
+			}
+			if fold {
+				folded[p] = true
+				foldedPreflop[p] = street == StreetPreflop
+			}
+
+			if street == StreetPreflop {
+				if ev.Amount > 0 {
+					vpip[p] = true
+				}
+				if aggressive {
+					pfr[p] = true
+				}
+				if raises == 1 && !seen3BetOpp[p] && p != opener {
+					seen3BetOpp[p] = true
+					c.ThreeBetOpps++
+					if aggressive {
+						c.ThreeBets++
+					}
+				}
+				if raises == 2 && p == opener && !seenFoldTo3Bet[p] {
+					seenFoldTo3Bet[p] = true
+					c.FoldTo3BetOpps++
+					if fold {
+						c.FoldTo3Bets++
+					}
+				}
+				if aggressive {
+					raises++
+					if opener < 0 {
+						opener = p
+					}
+					lastAggressor = p
+				}
+				continue
+			}
+
+			if aggressive {
+				c.PostflopAggr++
+			} else if call {
+				c.PostflopCalls++
+			}
+			if street == StreetFlop {
+				if p == lastAggressor && !flopBet && !seenCBetOpp {
+					seenCBetOpp = true
+					c.CBetOpps++
+					if aggressive {
+						c.CBets++
+					}
+				}
+				if aggressive {
+					flopBet = true
+				}
+			}
+		}
+	}
+
+	remaining := 0
+	for i := range dealt {
+		if dealt[i] && !folded[i] {
+			remaining++
+		}
+	}
+	showdown := remaining >= 2
+
+	for i := range counts {
+		if !dealt[i] {
+			continue
+		}
+		c := &counts[i]
+		c.Hands++
+		if vpip[i] {
+			c.VPIP++
+		}
+		if pfr[i] {
+			c.PFR++
+		}
+		if reachedFlop && !foldedPreflop[i] {
+			c.SawFlop++
+			if showdown && !folded[i] {
+				c.WentToShowdown++
+				if won[i] {
+					c.WonAtShowdown++
+				}
+			}
+		}
+		net := after[i] - hand.Stacks[i]
+		c.NetChips += net
+		if bigBlind > 0 {
+			c.NetBigBlinds += float64(net) / float64(bigBlind)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package game_test
+
+import (
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/internal/gametest"
+)
+
+func TestStatsShortBigBlind(t *testing.T) {
+	// The button calls the full big blind, the small blind completes, and the rest is checked down
+	gs := gametest.PlayHand(t, []int{1000, 1000, 4}, 0)
+
+	counts := gs.StatCounts()
+	for seat, c := range counts[:2] {
+		if c.VPIP != 1 || c.PFR != 0 {
+			t.Errorf("seat %d: VPIP %d PFR %d, want 1 and 0", seat, c.VPIP, c.PFR)
+		}
+	}
+}
+
+// TestStatCounts plays one scripted three-handed hand per case. A is the button and
+// first to act preflop, B the small blind and C the big blind. A's 4h 7h makes the best
+// flush on the 9h Th Jh Kh 2d board.
+func TestStatCounts(t *testing.T) {
+	raise := func(to int) game.Action { return game.Action{Type: game.ActionRaise, Amount: to} }
+	call := game.Action{Type: game.ActionCall}
+	check := game.Action{Type: game.ActionCheck}
+	fold := game.Action{Type: game.ActionFold}
+
+	tests := []struct {
+		name   string
+		script []game.Action
+		want   [3]game.StatCounts
+	}{
+		{
+			name:   "3-bet and fold to it",
+			script: []game.Action{raise(30), raise(90), fold, fold},
+			want: [3]game.StatCounts{
+				{Hands: 1, VPIP: 1, PFR: 1, FoldTo3BetOpps: 1, FoldTo3Bets: 1, NetChips: -30, NetBigBlinds: -3},
+				{Hands: 1, VPIP: 1, PFR: 1, ThreeBetOpps: 1, ThreeBets: 1, NetChips: 40, NetBigBlinds: 4},
+				{Hands: 1, NetChips: -10, NetBigBlinds: -1}, // Facing two raises isn't a 3-bet opportunity
+			},
+		},
+		{
+			name:   "3-bet called and checked down",
+			script: []game.Action{raise(30), raise(90), fold, call},
+			want: [3]game.StatCounts{
+				{Hands: 1, VPIP: 1, PFR: 1, FoldTo3BetOpps: 1, SawFlop: 1, WentToShowdown: 1, WonAtShowdown: 1, NetChips: 100, NetBigBlinds: 10},
+				{Hands: 1, VPIP: 1, PFR: 1, ThreeBetOpps: 1, ThreeBets: 1, CBetOpps: 1, SawFlop: 1, WentToShowdown: 1, NetChips: -90, NetBigBlinds: -9},
+				{Hands: 1, NetChips: -10, NetBigBlinds: -1}, // Facing two raises isn't a 3-bet opportunity
+			},
+		},
+		{
+			name:   "c-bet called to showdown",
+			script: []game.Action{raise(30), fold, call, check, raise(40), call},
+			want: [3]game.StatCounts{
+				{Hands: 1, VPIP: 1, PFR: 1, CBetOpps: 1, CBets: 1, PostflopAggr: 1, SawFlop: 1, WentToShowdown: 1, WonAtShowdown: 1, NetChips: 75, NetBigBlinds: 7.5},
+				{Hands: 1, ThreeBetOpps: 1, NetChips: -5, NetBigBlinds: -0.5},
+				{Hands: 1, VPIP: 1, ThreeBetOpps: 1, PostflopCalls: 1, SawFlop: 1, WentToShowdown: 1, NetChips: -70, NetBigBlinds: -7},
+			},
+		},
+		{
+			name:   "c-bet skipped, turn bet raised off",
+			script: []game.Action{raise(30), fold, call, check, check, raise(50), raise(150), fold},
+			want: [3]game.StatCounts{
+				{Hands: 1, VPIP: 1, PFR: 1, CBetOpps: 1, PostflopAggr: 1, SawFlop: 1, NetChips: 85, NetBigBlinds: 8.5},
+				{Hands: 1, ThreeBetOpps: 1, NetChips: -5, NetBigBlinds: -0.5},
+				{Hands: 1, VPIP: 1, ThreeBetOpps: 1, PostflopAggr: 1, SawFlop: 1, NetChips: -80, NetBigBlinds: -8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := gametest.PlayHand(t, []int{1000, 1000, 1000}, 0, tt.script...)
+			for seat, c := range gs.StatCounts() {
+				if c != tt.want[seat] {
+					t.Errorf("%s:\n got %+v\nwant %+v", gs.Players[seat].Name, c, tt.want[seat])
+				}
+			}
+		})
+	}
+}
+
+func TestNewPlayerStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts game.StatCounts
+		want   game.PlayerStats
+	}{
+		{
+			name: "no opportunities",
+			want: game.PlayerStats{Name: "A"},
+		},
+		{
+			name: "every stat",
+			counts: game.StatCounts{
+				Hands: 200, VPIP: 50, PFR: 30, ThreeBetOpps: 40, ThreeBets: 4, FoldTo3BetOpps: 10, FoldTo3Bets: 6,
+				CBetOpps: 20, CBets: 15, PostflopAggr: 30, PostflopCalls: 12, SawFlop: 40, WentToShowdown: 10,
+				WonAtShowdown: 6, NetChips: 300, NetBigBlinds: 30,
+			},
+			want: game.PlayerStats{
+				Name: "A", VPIP: 25, PFR: 15, ThreeBet: 10, FoldToThreeBet: 60, CBet: 75, AF: 2.5,
+				WTSD: 25, WSD: 60, BBPer100: 15,
+			},
+		},
+		{
+			name:   "aggression without calls",
+			counts: game.StatCounts{Hands: 4, PostflopAggr: 3, NetBigBlinds: -2},
+			want:   game.PlayerStats{Name: "A", AF: 3, BBPer100: -50},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := game.NewPlayerStats("A", tt.counts)
+			tt.want.Counts = tt.counts
+			if got != tt.want {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
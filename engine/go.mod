@@ -2,9 +2,10 @@ module github.com/rizzwareengineer/no-LLMit/engine
 
 go 1.22
 
+require github.com/gorilla/websocket v1.5.3
+
 require (
 	github.com/gorilla/mux v1.8.1 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/mattn/go-sqlite3 v1.14.22 // indirect
@@ -0,0 +1,238 @@
+// Package grading scores decisions once their hand is over and every hole card is
+// known. Heads-up short-stacked preflop spots are compared with the push/fold Nash
+// charts, heads-up river decisions with the solved river subgame (river.go), other
+// all-in call-offs with the equity the caller had against the hands actually held, and
+// remaining preflop decisions with the TAG bot's chart. Independently, clear blunders
+// are flagged: folding when checking was free, folding the nuts and calling on the
+// flop or turn while drawing dead. dataset.Store attaches a Grade to every record it
+// publishes.
+package grading
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/decider"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// Grade kinds, by the reference the decision was compared with
+const (
+	KindPushFold = "push_fold" // decider.PushFold charts
+	KindCallOff  = "call_off"  // Equity against the hands held versus the price
+	KindPreflop  = "preflop"   // decider.TAG opening and calling chart
+	KindRiver    = "river"     // Equilibrium of the heads-up river subgame (cfr)
+)
+
+// Blunders
+const (
+	BlunderFoldedForFree = "folded_for_free"
+	BlunderFoldedNuts    = "folded_nuts"
+	BlunderDrawingDead   = "called_drawing_dead"
+)
+
+// Preflop equities are estimated over this many boards
+const equityTrials = 2000
+
+type Grade struct {
+	Kind      string   `json:"kind,omitempty"`      // Empty when no reference applies
+	Correct   *bool    `json:"correct,omitempty"`   // Set when Kind is
+	Expected  string   `json:"expected,omitempty"`  // What the reference did
+	Equity    *float64 `json:"equity,omitempty"`    // Percent, against the hands opponents held
+	Required  *float64 `json:"required,omitempty"`  // Percent equity the price of calling needed
+	Frequency *float64 `json:"frequency,omitempty"` // Percent of the time the equilibrium plays this kind of action
+	Blunders  []string `json:"blunders,omitempty"`
+}
+
+// Decision grades the action a player at seat took (as ActionType.String(), e.g.
+// "ALL-IN") when shown prompt, given the complete record of the hand.
+func Decision(prompt *game.LLMPromptPayload, seat int, action string, hand game.HandRecord) *Grade {
+	s, ok := newSpot(prompt, seat, hand)
+	if !ok {
+		return nil
+	}
+
+	g := &Grade{}
+	switch {
+	case decider.PushFoldSpot(prompt) && len(s.opponents) == 1:
+		// The charts are heads-up; multiway spots are graded like deeper ones
+		g.compare(KindPushFold, action, decider.PushFold{}, prompt, s)
+	case g.river(prompt, action, s):
+		// Graded against the solved subgame
+	case s.callOff():
+		g.Kind = KindCallOff
+		equity := roundPercent(s.equity())
+		required := roundPercent(float64(s.toCall) / float64(s.pot+s.toCall))
+		called := action != "FOLD"
+		correct := called == (equity >= required)
+		g.Equity, g.Required, g.Correct = &equity, &required, &correct
+		g.Expected = "FOLD"
+		if equity >= required {
+			g.Expected = "CALL"
+		}
+	case len(s.board) == 0:
+		g.compare(KindPreflop, action, decider.TAG{}, prompt, s)
+	}
+
+	switch {
+	case action == "FOLD" && s.canCheck:
+		g.Blunders = append(g.Blunders, BlunderFoldedForFree)
+	case action == "FOLD" && game.IsNuts(s.hole, s.board):
+		g.Blunders = append(g.Blunders, BlunderFoldedNuts)
+	case (action == "CALL" || action == "ALL-IN" && s.closesAction()) && len(s.contesting) > 0:
+		// On the river any losing call would count, which is hindsight rather than a blunder
+		if (len(s.board) == 3 || len(s.board) == 4) && s.equity() == 0 {
+			g.Blunders = append(g.Blunders, BlunderDrawingDead)
+		}
+	}
+	return g
+}
+
+// compare grades action against what a reference bot does in the same spot. Only the
+// kind of action counts (fold or check, call, bet or raise), not the size.
+func (g *Grade) compare(kind, action string, reference decider.Decider, prompt *game.LLMPromptPayload, s *spot) {
+	ref, err := reference.Decide(context.Background(), prompt)
+	if err != nil {
+		return
+	}
+	g.Kind = kind
+	g.Expected = ref.Action
+	correct := s.actionClass(action) == s.actionClass(ref.Action)
+	g.Correct = &correct
+}
+
+func (s *spot) actionClass(action string) string {
+	switch action {
+	case "FOLD", "CHECK":
+		return "passive"
+	case "CALL":
+		return "call"
+	case "ALL-IN", "ALL_IN":
+		if s.closesAction() {
+			return "call" // Nothing is left to raise
+		}
+		return "aggressive"
+	default: // BET, RAISE
+		return "aggressive"
+	}
+}
+
+func roundPercent(fraction float64) float64 {
+	return float64(int(fraction*1000+0.5)) / 10
+}
+
+// spot is a decision with everything the hand revealed afterwards.
+type spot struct {
+	hole       [2]game.Card
+	board      []game.Card
+	pot        int
+	toCall     int
+	stack      int
+	canCheck   bool
+	allInCall  bool     // Calling puts us all in
+	opponents  []string // Still in the hand when we decided
+	allIn      map[string]bool
+	contesting [][2]game.Card // Hole cards of opponents who never folded this hand
+}
+
+func newSpot(prompt *game.LLMPromptPayload, seat int, hand game.HandRecord) (*spot, bool) {
+	if prompt == nil || len(prompt.YourCards) != 2 {
+		return nil, false
+	}
+	s := &spot{pot: prompt.Pot, allIn: make(map[string]bool)}
+	for i, str := range prompt.YourCards {
+		c, err := game.ParseCard(str)
+		if err != nil {
+			return nil, false
+		}
+		s.hole[i] = c
+	}
+	for _, str := range prompt.CommunityCards {
+		c, err := game.ParseCard(str)
+		if err != nil {
+			return nil, false
+		}
+		s.board = append(s.board, c)
+	}
+
+	folded := make(map[string]bool)
+	for _, a := range prompt.ActionsThisHand {
+		switch a.Action {
+		case "FOLD":
+			folded[a.Player] = true
+		case "ALL-IN":
+			s.allIn[a.Player] = true
+		}
+	}
+
+	holeCards := hand.HoleCards(len(hand.Stacks))
+	dealt := func(i int) bool { return i >= 0 && i < len(holeCards) && len(holeCards[i]) == 2 }
+
+	seats := make(map[string]int)
+	for _, p := range prompt.Players {
+		seats[p.Name] = p.Seat
+		switch {
+		case p.Name == prompt.YourName:
+			s.stack = p.Stack
+		case !folded[p.Name] && dealt(p.Seat):
+			s.opponents = append(s.opponents, p.Name)
+			if p.Stack == 0 {
+				s.allIn[p.Name] = true // Also calls that took the whole stack
+			}
+		}
+	}
+
+	hasCall := false
+	for _, a := range prompt.ValidActions {
+		switch a.Type {
+		case "CHECK":
+			s.canCheck = true
+		case "CALL":
+			hasCall = true
+			s.toCall = a.Amount
+		}
+	}
+	if !hasCall && !s.canCheck {
+		s.toCall, s.allInCall = s.stack, true
+	}
+	s.allInCall = s.allInCall || s.toCall >= s.stack
+
+	// Opponents who folded later no longer contest the pot
+	foldedLater := make(map[int]bool)
+	for _, ev := range hand.Events {
+		if ev.Type == game.EventAction && ev.Resolved != nil && ev.Resolved.Type == game.ActionFold {
+			foldedLater[ev.PlayerIdx] = true
+		}
+	}
+	for _, name := range s.opponents {
+		i := seats[name]
+		if i == seat || foldedLater[i] {
+			continue
+		}
+		s.contesting = append(s.contesting, [2]game.Card{holeCards[i][0], holeCards[i][1]})
+	}
+	return s, true
+}
+
+// closesAction reports facing a bet where calling ends the betting for us: it either
+// puts us all in or comes from opponents who are all in themselves.
+func (s *spot) closesAction() bool {
+	if s.toCall == 0 {
+		return false
+	}
+	return s.allInCall || !slices.ContainsFunc(s.opponents, func(name string) bool {
+		return !s.allIn[name]
+	})
+}
+
+// callOff is a spot closing the action against at least one hand that went on to
+// contest the pot.
+func (s *spot) callOff() bool {
+	return s.closesAction() && len(s.contesting) > 0
+}
+
+func (s *spot) equity() float64 {
+	rng := rand.New(rand.NewSource(1)) // Same grade every time the hand is graded
+	return game.MultiwayEquity(s.hole, s.contesting, s.board, equityTrials, rng)
+}
@@ -0,0 +1,213 @@
+package grading
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// headsUp builds a heads-up decision for "hero" (seat 0) against "villain" (seat 1)
+// at 5/10, both having started the hand with 1000. actions are this hand's actions
+// after the blinds and stack is what hero has left behind. holes are each seat's hole
+// cards, space separated, for the hand record.
+func headsUp(t *testing.T, holes [2]string, board string, pot, stack int, actions []game.LLMAction, valid []game.LLMValidAction) (*game.LLMPromptPayload, game.HandRecord) {
+	t.Helper()
+	names := []string{"hero", "villain"}
+	prompt := &game.LLMPromptPayload{
+		YourName:  "hero",
+		YourCards: strings.Fields(holes[0]),
+		Pot:       pot,
+		ActionsThisHand: append([]game.LLMAction{
+			{Player: "villain", Action: "post", Amount: 5, Street: "preflop", Seat: 1, StackBefore: 1000},
+			{Player: "hero", Action: "post", Amount: 10, Street: "preflop", Seat: 0, StackBefore: 1000},
+		}, actions...),
+		ValidActions: valid,
+	}
+	if board != "" {
+		prompt.CommunityCards = strings.Fields(board)
+	}
+
+	hand := game.HandRecord{Number: 1, Button: 1, Stacks: []int{1000, 1000}}
+	for seat, name := range names {
+		stackLeft := stack
+		for _, a := range actions {
+			if a.Player == name && name != "hero" {
+				stackLeft = a.StackBefore - a.Amount
+			}
+		}
+		prompt.Players = append(prompt.Players, game.LLMPlayer{Name: name, Seat: seat, Stack: stackLeft})
+
+		var cards []game.Card
+		for _, s := range strings.Fields(holes[seat]) {
+			c, err := game.ParseCard(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cards = append(cards, c)
+		}
+		hand.Events = append(hand.Events, game.Event{Type: game.EventDeal, HandNumber: 1, PlayerIdx: seat, Cards: cards})
+	}
+	return prompt, hand
+}
+
+func TestDecision(t *testing.T) {
+	// Preflop hero is the big blind facing a raise to 30
+	preflop := []game.LLMAction{{Player: "villain", Action: "RAISE", Amount: 30, Street: "preflop", Seat: 1, StackBefore: 995, PotBefore: 15}}
+	preflopValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 20}, {Type: "RAISE", Min: 50, Max: 1000}}
+
+	// On the flop villain holds quads and bets 50 into 100; kings full can't catch up
+	flop := []game.LLMAction{
+		{Player: "villain", Action: "CALL", Amount: 5, Street: "preflop", Seat: 1, StackBefore: 995, PotBefore: 15},
+		{Player: "hero", Action: "CHECK", Street: "preflop", Seat: 0, StackBefore: 990, PotBefore: 20},
+		{Player: "villain", Action: "RAISE", Amount: 50, Street: "flop", Seat: 1, StackBefore: 950, PotBefore: 100},
+	}
+	flopValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 50}, {Type: "RAISE", Min: 100, Max: 950}}
+
+	// On the river hero holds the nuts, a 6-high straight, facing a pot-sized bet. At
+	// equilibrium the nuts never fold and raise rather than call.
+	river := []game.LLMAction{
+		{Player: "villain", Action: "CALL", Amount: 5, Street: "preflop", Seat: 1, StackBefore: 995, PotBefore: 15},
+		{Player: "hero", Action: "CHECK", Street: "preflop", Seat: 0, StackBefore: 990, PotBefore: 20},
+		{Player: "villain", Action: "RAISE", Amount: 100, Street: "river", Seat: 1, StackBefore: 950, PotBefore: 100},
+	}
+	riverValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 100}, {Type: "RAISE", Min: 200, Max: 950}}
+
+	// Heads-up at 8 BB hero is the big blind facing a shove
+	shove := []game.LLMAction{{Player: "villain", Action: "ALL-IN", Amount: 75, Street: "preflop", Seat: 1, StackBefore: 75, PotBefore: 15}}
+	shoveValid := []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 70}}
+
+	tests := []struct {
+		name     string
+		holes    [2]string
+		board    string
+		pot      int
+		stack    int
+		actions  []game.LLMAction
+		valid    []game.LLMValidAction
+		action   string
+		kind     string
+		correct  bool
+		expected string
+		blunders []string
+	}{
+		{
+			name:  "preflop, trash defended against the chart",
+			holes: [2]string{"7h 2c", "As Kd"}, pot: 45, stack: 990,
+			actions: preflop, valid: preflopValid,
+			action: "CALL", kind: KindPreflop, correct: false, expected: "FOLD",
+		},
+		{
+			name:  "preflop, premium 3-bet like the chart",
+			holes: [2]string{"Ah Ac", "7s 6s"}, pot: 45, stack: 990,
+			actions: preflop, valid: preflopValid,
+			action: "RAISE", kind: KindPreflop, correct: true, expected: "RAISE",
+		},
+		{
+			name:  "push/fold, called a heads-up shove with aces",
+			holes: [2]string{"Ah Ac", "7s 6s"}, pot: 90, stack: 70,
+			actions: shove, valid: shoveValid,
+			action: "CALL", kind: KindPushFold, correct: true, expected: "CALL",
+		},
+		{
+			name:  "flop, called drawing dead",
+			holes: [2]string{"Kh Kd", "As Ac"}, board: "Ah Ad Kc", pot: 150, stack: 950,
+			actions: flop, valid: flopValid,
+			action: "CALL", blunders: []string{BlunderDrawingDead},
+		},
+		{
+			name:  "river, folded the nuts",
+			holes: [2]string{"6h 4h", "Kh Qh"}, board: "Ks 8d 5c 2h 3s", pot: 200, stack: 950,
+			actions: river, valid: riverValid,
+			action: "FOLD", kind: KindRiver, correct: false, blunders: []string{BlunderFoldedNuts},
+		},
+		{
+			name:  "river, shoved the nuts",
+			holes: [2]string{"6h 4h", "Kh Qh"}, board: "Ks 8d 5c 2h 3s", pot: 200, stack: 950,
+			actions: river, valid: riverValid,
+			action: "ALL-IN", kind: KindRiver, correct: true, expected: "ALL-IN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, hand := headsUp(t, tt.holes, tt.board, tt.pot, tt.stack, tt.actions, tt.valid)
+			g := Decision(prompt, 0, tt.action, hand)
+			if g == nil {
+				t.Fatal("no grade")
+			}
+			if g.Kind != tt.kind {
+				t.Errorf("kind = %q, want %q", g.Kind, tt.kind)
+			}
+			if tt.kind != "" && (g.Correct == nil || *g.Correct != tt.correct) {
+				t.Errorf("correct = %v, want %v (expected %s)", g.Correct, tt.correct, g.Expected)
+			}
+			if tt.expected != "" && g.Expected != tt.expected {
+				t.Errorf("expected = %q, want %q", g.Expected, tt.expected)
+			}
+			if !slices.Equal(g.Blunders, tt.blunders) {
+				t.Errorf("blunders = %v, want %v", g.Blunders, tt.blunders)
+			}
+		})
+	}
+}
+
+// TestDecisionMultiwayShortStack grades the under-the-gun seat four-handed at 6 BB. The
+// push/fold charts are heads-up, so they aren't the reference here.
+func TestDecisionMultiwayShortStack(t *testing.T) {
+	tests := []struct {
+		name     string
+		hole     string
+		action   string
+		expected string
+	}{
+		{name: "premium shoved", hole: "Ah Ad", action: "ALL-IN", expected: "RAISE"},
+		{name: "trash folded", hole: "7h 2c", action: "FOLD", expected: "FOLD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := &game.LLMPromptPayload{
+				YourName:  "hero",
+				YourCards: strings.Fields(tt.hole),
+				Pot:       15,
+				Players: []game.LLMPlayer{
+					{Name: "btn", Seat: 0, Stack: 60, Position: "BTN"},
+					{Name: "sb", Seat: 1, Stack: 55, Position: "SB"},
+					{Name: "bb", Seat: 2, Stack: 50, Position: "BB"},
+					{Name: "hero", Seat: 3, Stack: 60, Position: "UTG"},
+				},
+				ActionsThisHand: []game.LLMAction{
+					{Player: "sb", Action: "post", Amount: 5, Street: "preflop", Seat: 1, StackBefore: 60},
+					{Player: "bb", Action: "post", Amount: 10, Street: "preflop", Seat: 2, StackBefore: 60},
+				},
+				ValidActions: []game.LLMValidAction{{Type: "FOLD"}, {Type: "CALL", Amount: 10}, {Type: "RAISE", Min: 20, Max: 60}, {Type: "ALL-IN", Amount: 60}},
+			}
+			hand := game.HandRecord{Number: 1, Stacks: []int{60, 60, 60, 60}}
+			for seat, hole := range []string{"Kc Qc", "9s 9d", "Js Ts", tt.hole} {
+				var cards []game.Card
+				for _, s := range strings.Fields(hole) {
+					c, err := game.ParseCard(s)
+					if err != nil {
+						t.Fatal(err)
+					}
+					cards = append(cards, c)
+				}
+				hand.Events = append(hand.Events, game.Event{Type: game.EventDeal, HandNumber: 1, PlayerIdx: seat, Cards: cards})
+			}
+
+			g := Decision(prompt, 3, tt.action, hand)
+			if g == nil {
+				t.Fatal("no grade")
+			}
+			if g.Kind == KindPushFold {
+				t.Errorf("graded against the heads-up push/fold charts")
+			}
+			if g.Correct == nil || !*g.Correct {
+				t.Errorf("%s graded %v, want correct (kind %q, expected %s)", tt.action, g.Correct, g.Kind, g.Expected)
+			}
+			if g.Expected != tt.expected {
+				t.Errorf("expected = %q, want %q", g.Expected, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// This file grades heads-up river decisions against the equilibrium of the river
+// subgame, solved with cfr. Preflop and the flop and turn aren't solved: cfr only
+// handles river subgames, where no cards are left to come. The grader doesn't model how
+// earlier streets narrowed each range, so both players are given any two cards the
+// board allows, sampled down to riverRangeSize combos to keep the solve well under a
+// second.
+package grading
+
+import (
+	"math/rand"
+	"slices"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/cfr"
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+const (
+	riverRangeSize  = 100
+	riverIterations = 100
+	riverMinFreq    = 0.1 // An action the equilibrium takes less often than this is wrong
+)
+
+var riverBetSizes = []float64{0.5, 1}
+
+// river grades a heads-up river decision, reporting whether it could.
+func (g *Grade) river(prompt *game.LLMPromptPayload, action string, s *spot) bool {
+	if len(s.board) != 5 || len(s.opponents) != 1 {
+		return false
+	}
+	opponent := s.opponents[0]
+
+	// The subgame starts before anyone bet on the river
+	pot := prompt.Pot
+	stacks := make(map[string]int)
+	bigBlind := 0
+	var history []game.Action
+	first := prompt.YourName
+	for _, p := range prompt.Players {
+		stacks[p.Name] = p.Stack
+	}
+	seen := make(map[string]bool)
+	for _, a := range prompt.ActionsThisHand {
+		if a.Action == "post" {
+			bigBlind = max(bigBlind, a.Amount)
+		}
+		if a.Street != game.StreetRiver.String() {
+			continue
+		}
+		if len(history) == 0 {
+			pot, first = a.PotBefore, a.Player
+		}
+		if !seen[a.Player] {
+			seen[a.Player] = true
+			stacks[a.Player] = a.StackBefore
+		}
+		history = append(history, riverAction(a))
+	}
+	stack := min(stacks[prompt.YourName], stacks[opponent])
+	if stack == 0 || bigBlind == 0 {
+		return false
+	}
+
+	hero := 0
+	if first != prompt.YourName {
+		hero = 1
+	}
+	rng := rand.New(rand.NewSource(1)) // Same grade every time the hand is graded
+	var ranges [2]cfr.Range
+	for p := range ranges {
+		ranges[p] = sampleRange(s.board, rng)
+	}
+	// Our range must hold our hand; drop the combos that share a card with it
+	ranges[hero] = slices.DeleteFunc(ranges[hero], func(c cfr.Combo) bool { return game.CombosOverlap(c.Cards, s.hole) })
+	ranges[hero] = append(ranges[hero], cfr.Combo{Cards: s.hole, Weight: 1})
+
+	solution, err := cfr.Solve(cfr.Config{
+		State:      cfr.RiverState(s.board, pot, stack, bigBlind),
+		Ranges:     ranges,
+		BetSizes:   riverBetSizes,
+		Iterations: riverIterations,
+	})
+	if err != nil {
+		return false
+	}
+	actions, freqs, err := solution.Strategy(history, s.hole)
+	if err != nil {
+		return false
+	}
+
+	// Like compare, only the kind of action counts
+	class := s.actionClass(action)
+	var freq, best float64
+	for i, a := range actions {
+		if s.actionClass(a.Type.String()) == class {
+			freq += freqs[i]
+		}
+		if freqs[i] > best {
+			best = freqs[i]
+			g.Expected = a.Type.String()
+		}
+	}
+	g.Kind = KindRiver
+	frequency := roundPercent(freq)
+	correct := freq >= riverMinFreq
+	g.Frequency, g.Correct = &frequency, &correct
+	return true
+}
+
+// riverAction is a as the subgame's tree matches it. Raise and all-in amounts are
+// raise-to totals for the street.
+func riverAction(a game.LLMAction) game.Action {
+	switch a.Action {
+	case "CHECK":
+		return game.Action{Type: game.ActionCheck}
+	case "CALL":
+		return game.Action{Type: game.ActionCall}
+	case "ALL-IN":
+		return game.Action{Type: game.ActionAllIn, Amount: a.Amount}
+	case "FOLD":
+		return game.Action{Type: game.ActionFold}
+	default: // RAISE
+		return game.Action{Type: game.ActionRaise, Amount: a.Amount}
+	}
+}
+
+// sampleRange picks riverRangeSize combos, equally weighted, that don't use the board.
+func sampleRange(board []game.Card, rng *rand.Rand) cfr.Range {
+	var deck []game.Card
+	for suit := game.Hearts; suit <= game.Spades; suit++ {
+		for rank := game.Two; rank <= game.Ace; rank++ {
+			if c := (game.Card{Rank: rank, Suit: suit}); !slices.Contains(board, c) {
+				deck = append(deck, c)
+			}
+		}
+	}
+	var all cfr.Range
+	for i := range deck {
+		for j := i + 1; j < len(deck); j++ {
+			all = append(all, cfr.Combo{Cards: [2]game.Card{deck[i], deck[j]}, Weight: 1})
+		}
+	}
+	rng.Shuffle(len(all), func(i, j int) { all[i], all[j] = all[j], all[i] })
+	return all[:riverRangeSize]
+}
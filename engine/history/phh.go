@@ -0,0 +1,604 @@
+// This file exports and imports the Poker Hand History (PHH) format used by academic
+// datasets (https://phh.readthedocs.io). Each hand becomes a TOML document; a whole game
+// is written as a .phhs collection with one [n] table per hand. ReadPHH parses either and
+// replays every hand through GameState, so an import doubles as a validation of the file.
+//
+// PHH orders players from the seat left of the button, button last. The engine posts the
+// small blind left of the button even heads-up, which matches that order directly.
+package history
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+const unknownCards = "????"
+
+// WritePHH writes hands, completed hands of gs, as a .phhs collection for seat (see
+// AllSeats).
+func WritePHH(w io.Writer, gs *game.GameState, hands []game.HandRecord, seat int) error {
+	for _, hand := range hands {
+		section := fmt.Sprintf("[%d]\n%s\n", hand.Number, FormatPHH(gs, hand, seat))
+		if _, err := io.WriteString(w, section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatPHH renders a single completed hand as a PHH document, as seen by seat.
+func FormatPHH(gs *game.GameState, hand game.HandRecord, seat int) string {
+	holeCards := hand.HoleCards(len(gs.Players))
+	order := phhOrder(hand.Button, holeCards)
+	pIndex := make(map[int]int, len(order)) // engine index -> PHH player number (1-based)
+	for k, idx := range order {
+		pIndex[idx] = k + 1
+	}
+
+	var (
+		names, seats     []string
+		antes, blinds    []string
+		starting, finish []string
+		actions          []string
+		streetBets       = make([]int, len(gs.Players))
+		currentBet       = 0
+		folded           = make(map[int]bool)
+		boardDealt       = 0
+		blind            = make(map[int]int)
+	)
+
+	// The blinds as set for the table, not the chips posted: a short stack posts all-in
+	// for less, which readers derive from its starting stack
+	posts := 0
+	for _, ev := range hand.Events {
+		if ev.Type == game.EventPost {
+			blind[ev.PlayerIdx] = []int{gs.Stakes.SmallBlind, gs.Stakes.BigBlind}[min(posts, 1)]
+			posts++
+		}
+	}
+
+	for _, idx := range order {
+		names = append(names, tomlString(gs.Players[idx].Name))
+		seats = append(seats, strconv.Itoa(gs.Players[idx].SeatPosition+1))
+		antes = append(antes, "0")
+		blinds = append(blinds, strconv.Itoa(blind[idx]))
+		starting = append(starting, strconv.Itoa(hand.Stacks[idx]))
+	}
+
+	for _, ev := range hand.Events {
+		switch ev.Type {
+		case game.EventPost:
+			// Calling a short big blind still takes the full big blind
+			streetBets[ev.PlayerIdx] += ev.Amount
+			currentBet = gs.Stakes.BigBlind
+
+		case game.EventAction:
+			if ev.Resolved == nil {
+				continue
+			}
+			p := pIndex[ev.PlayerIdx]
+			total := streetBets[ev.PlayerIdx] + ev.Amount
+			switch {
+			case ev.Resolved.Type == game.ActionFold:
+				actions = append(actions, fmt.Sprintf("p%d f", p))
+				folded[ev.PlayerIdx] = true
+			case total <= currentBet:
+				actions = append(actions, fmt.Sprintf("p%d cc", p))
+			default:
+				actions = append(actions, fmt.Sprintf("p%d cbr %d", p, total))
+			}
+			streetBets[ev.PlayerIdx] = total
+			currentBet = max(currentBet, total)
+
+		case game.EventStreet:
+			for i := range streetBets {
+				streetBets[i] = 0
+			}
+			currentBet = 0
+			for len(ev.Cards) > 0 {
+				n := 1
+				if boardDealt == 0 {
+					n = 3
+				}
+				n = min(n, len(ev.Cards))
+				actions = append(actions, "d db "+concatCards(ev.Cards[:n]))
+				boardDealt += n
+				ev.Cards = ev.Cards[n:]
+			}
+		}
+	}
+
+	// Hole cards are dealt before any other action
+	var deals []string
+	for _, idx := range order {
+		cards := concatCards(holeCards[idx])
+		if !dealtFaceUp(seat, idx) {
+			cards = unknownCards
+		}
+		deals = append(deals, fmt.Sprintf("d dh p%d %s", pIndex[idx], cards))
+	}
+	actions = append(deals, actions...)
+
+	remaining := 0
+	for _, idx := range order {
+		if !folded[idx] {
+			remaining++
+		}
+	}
+	if remaining > 1 {
+		for _, idx := range order {
+			if !folded[idx] {
+				actions = append(actions, fmt.Sprintf("p%d sm %s", pIndex[idx], concatCards(holeCards[idx])))
+			}
+		}
+	}
+
+	finishing := finishingStacks(gs, hand)
+	for _, idx := range order {
+		finish = append(finish, strconv.Itoa(finishing[idx]))
+	}
+
+	quoted := make([]string, len(actions))
+	for i, a := range actions {
+		quoted[i] = "  " + tomlString(a) + ","
+	}
+
+	var b strings.Builder
+	b.WriteString(`variant = "NT"` + "\n")
+	b.WriteString("ante_trimming_status = true\n")
+	fmt.Fprintf(&b, "antes = [%s]\n", strings.Join(antes, ", "))
+	fmt.Fprintf(&b, "blinds_or_straddles = [%s]\n", strings.Join(blinds, ", "))
+	fmt.Fprintf(&b, "min_bet = %d\n", gs.Stakes.BigBlind)
+	fmt.Fprintf(&b, "starting_stacks = [%s]\n", strings.Join(starting, ", "))
+	fmt.Fprintf(&b, "actions = [\n%s\n]\n", strings.Join(quoted, "\n"))
+	fmt.Fprintf(&b, "players = [%s]\n", strings.Join(names, ", "))
+	fmt.Fprintf(&b, "finishing_stacks = [%s]\n", strings.Join(finish, ", "))
+	fmt.Fprintf(&b, "seats = [%s]\n", strings.Join(seats, ", "))
+	fmt.Fprintf(&b, "seat_count = %d\n", len(gs.Players))
+	fmt.Fprintf(&b, "table = %s\n", tomlString(gs.ID))
+	fmt.Fprintf(&b, "hand = %d\n", hand.Number)
+	if len(hand.Events) > 0 {
+		t := hand.Events[0].Time.UTC()
+		fmt.Fprintf(&b, "year = %d\nmonth = %d\nday = %d\n", t.Year(), int(t.Month()), t.Day())
+		fmt.Fprintf(&b, "time = %s\ntime_zone = \"UTC\"\n", tomlString(t.Format("15:04:05")))
+	}
+	return b.String()
+}
+
+// finishingStacks are the stacks the next hand started with, or the current stacks
+// for the most recent hand.
+func finishingStacks(gs *game.GameState, hand game.HandRecord) []int {
+	for _, h := range gs.Hands() {
+		if h.Number == hand.Number+1 {
+			return h.Stacks
+		}
+	}
+	stacks := make([]int, len(gs.Players))
+	for i, p := range gs.Players {
+		stacks[i] = p.Stack
+	}
+	return stacks
+}
+
+// phhOrder lists the dealt-in players starting left of the button, button last.
+func phhOrder(button int, holeCards [][]game.Card) []int {
+	var order []int
+	n := len(holeCards)
+	for i := 1; i <= n; i++ {
+		idx := (button + i) % n
+		if holeCards[idx] != nil {
+			order = append(order, idx)
+		}
+	}
+	return order
+}
+
+func concatCards(cards []game.Card) string {
+	var b strings.Builder
+	for _, c := range cards {
+		b.WriteString(c.String())
+	}
+	return b.String()
+}
+
+func parseCardRun(s string) ([]game.Card, error) {
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("invalid cards %q", s)
+	}
+	var cards []game.Card
+	for i := 0; i < len(s); i += 2 {
+		if s[i:i+2] == "??" {
+			cards = append(cards, game.Card{}) // Unknown, filled in from the rest of the deck
+			continue
+		}
+		c, err := game.ParseCard(s[i : i+2])
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, c)
+	}
+	return cards, nil
+}
+
+// PHHHand is one imported hand after replaying it through GameState.
+type PHHHand struct {
+	Name  string          `json:"name"` // Table name in a .phhs collection, empty for a single .phh
+	State *game.GameState `json:"-"`
+}
+
+// ReadPHH parses a .phh document or .phhs collection and replays every hand through a
+// fresh GameState. It fails on the first hand whose actions are illegal for the engine
+// or whose replayed finishing stacks differ from the file.
+func ReadPHH(r io.Reader) ([]PHHHand, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseTOML(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(doc.TableOrder) == 0 {
+		gs, err := replayPHH(doc.Root)
+		if err != nil {
+			return nil, err
+		}
+		return []PHHHand{{State: gs}}, nil
+	}
+
+	var hands []PHHHand
+	for _, name := range doc.TableOrder {
+		gs, err := replayPHH(doc.Tables[name])
+		if err != nil {
+			return nil, fmt.Errorf("hand [%s]: %w", name, err)
+		}
+		hands = append(hands, PHHHand{Name: name, State: gs})
+	}
+	return hands, nil
+}
+
+// replayPHH replays one parsed PHH hand. The returned game is in test mode with the
+// button on the last player, and its event log can be exported again.
+func replayPHH(t tomlTable) (*game.GameState, error) {
+	if v, _ := t["variant"].(string); v != "NT" {
+		return nil, fmt.Errorf("unsupported variant %q (only NT, no-limit Texas hold'em)", v)
+	}
+
+	stacks, err := intArray(t, "starting_stacks")
+	if err != nil {
+		return nil, err
+	}
+	n := len(stacks)
+	if n < 2 || n > 9 {
+		return nil, fmt.Errorf("player count must be between 2 and 9, got %d", n)
+	}
+
+	if antes, err := intArray(t, "antes"); err == nil {
+		for _, a := range antes {
+			if a != 0 {
+				return nil, fmt.Errorf("antes are not supported")
+			}
+		}
+	}
+
+	blinds, err := intArray(t, "blinds_or_straddles")
+	if err != nil {
+		return nil, err
+	}
+	if len(blinds) != n || blinds[0] > blinds[1] {
+		return nil, fmt.Errorf("blinds_or_straddles must be [small, big, 0, ...]")
+	}
+	for _, b := range blinds[2:] {
+		if b != 0 {
+			return nil, fmt.Errorf("straddles are not supported")
+		}
+	}
+
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("p%d", i+1)
+	}
+	if players, ok := t["players"].([]any); ok && len(players) == n {
+		for i, p := range players {
+			if s, ok := p.(string); ok {
+				names[i] = s
+			}
+		}
+	}
+
+	rawActions, ok := t["actions"].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing actions")
+	}
+	actions := make([]string, len(rawActions))
+	for i, a := range rawActions {
+		s, ok := a.(string)
+		if !ok {
+			return nil, fmt.Errorf("action %d is not a string", i)
+		}
+		actions[i] = strings.TrimSpace(s)
+	}
+
+	deck, err := phhDeck(n, actions)
+	if err != nil {
+		return nil, err
+	}
+
+	config := game.GameConfig{
+		PlayerNames:    names,
+		StartingStacks: stacks,
+		Stakes:         game.Stakes{SmallBlind: blinds[0], BigBlind: blinds[1]},
+		Mode:           game.ModeTest,
+	}
+	gs, err := game.Replay(config, []game.Event{{
+		Type:       game.EventHandStart,
+		HandNumber: 1,
+		Button:     n - 1,
+		Deck:       deck,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &phhReplay{gs: gs}
+	for i, a := range actions {
+		if err := replay.apply(a); err != nil {
+			return nil, fmt.Errorf("action %d %q: %w", i, a, err)
+		}
+	}
+
+	if finishing, err := intArray(t, "finishing_stacks"); err == nil && gs.IsHandComplete() {
+		for i, want := range finishing {
+			if i < n && gs.Players[i].Stack != want {
+				return nil, fmt.Errorf("finishing stack of p%d: replay has %d, file has %d", i+1, gs.Players[i].Stack, want)
+			}
+		}
+	}
+
+	return gs, nil
+}
+
+// phhDeck builds a deck order that deals the file's hole and board cards in the order
+// StartHand and AdvanceStreet draw them, filling unknown cards and burns with the rest.
+func phhDeck(n int, actions []string) ([]game.Card, error) {
+	holes := make([][]game.Card, n)
+	var board []game.Card
+
+	var shown []string
+	for _, a := range actions {
+		f := strings.Fields(a)
+		if len(f) >= 3 && f[1] == "sm" {
+			shown = append(shown, a)
+		}
+		if len(f) < 3 || f[0] != "d" {
+			continue
+		}
+		switch f[1] {
+		case "dh":
+			p, err := phhPlayer(f[2], n)
+			if err != nil || len(f) < 4 {
+				return nil, fmt.Errorf("invalid deal %q", a)
+			}
+			cards, err := parseCardRun(f[3])
+			if err != nil || len(cards) != 2 {
+				return nil, fmt.Errorf("invalid hole cards in %q", a)
+			}
+			holes[p] = cards
+		case "db":
+			cards, err := parseCardRun(f[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid board in %q", a)
+			}
+			board = append(board, cards...)
+		}
+	}
+
+	// Cards shown at showdown fill in hole cards dealt as "????"
+	for _, a := range shown {
+		f := strings.Fields(a)
+		p, err := phhPlayer(f[0], n)
+		if err != nil {
+			return nil, err
+		}
+		cards, err := parseCardRun(f[2])
+		if err != nil || len(cards) != 2 || holes[p] == nil {
+			continue
+		}
+		for i, c := range holes[p] {
+			if c == (game.Card{}) {
+				holes[p][i] = cards[i]
+			}
+		}
+	}
+
+	used := make(map[game.Card]bool)
+	for _, h := range holes {
+		for _, c := range h {
+			if c != (game.Card{}) {
+				used[c] = true
+			}
+		}
+	}
+	for _, c := range board {
+		used[c] = true
+	}
+	var spare []game.Card
+	for suit := game.Hearts; suit <= game.Spades; suit++ {
+		for rank := game.Two; rank <= game.Ace; rank++ {
+			if c := (game.Card{Rank: rank, Suit: suit}); !used[c] {
+				spare = append(spare, c)
+			}
+		}
+	}
+	next := func(c game.Card) game.Card {
+		if c != (game.Card{}) {
+			return c
+		}
+		c, spare = spare[0], spare[1:]
+		return c
+	}
+
+	var deck []game.Card
+	for round := 0; round < 2; round++ {
+		for p := 0; p < n; p++ {
+			var c game.Card
+			if holes[p] != nil {
+				c = holes[p][round]
+			}
+			deck = append(deck, next(c))
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if i == 0 || i >= 3 {
+			deck = append(deck, next(game.Card{})) // Burn before flop, turn and river
+		}
+		var c game.Card
+		if i < len(board) {
+			c = board[i]
+		}
+		deck = append(deck, next(c))
+	}
+	deck = append(deck, spare...)
+
+	if len(deck) != 52 {
+		return nil, fmt.Errorf("hand uses duplicate cards")
+	}
+	return deck, nil
+}
+
+// phhReplay applies PHH actions to a game, tracking how much of the board the file has
+// dealt so far (the engine runs out the whole board at once when players are all-in).
+type phhReplay struct {
+	gs    *game.GameState
+	board int
+}
+
+func (r *phhReplay) apply(a string) error {
+	gs := r.gs
+	f := strings.Fields(a)
+	if len(f) < 2 {
+		return fmt.Errorf("malformed action")
+	}
+
+	if f[0] == "d" {
+		if f[1] != "db" || len(f) < 3 {
+			return nil // Hole cards are already in the deck order
+		}
+		cards, err := parseCardRun(f[2])
+		if err != nil {
+			return err
+		}
+		r.board += len(cards)
+		if len(gs.CommunityCards) < r.board {
+			if !gs.NeedToAdvanceStreet() {
+				return fmt.Errorf("board dealt before betting round finished")
+			}
+			if err := gs.AdvanceStreet(); err != nil {
+				return err
+			}
+		}
+		if len(gs.CommunityCards) < r.board {
+			return fmt.Errorf("engine dealt %d board cards, file has %d", len(gs.CommunityCards), r.board)
+		}
+		return nil
+	}
+
+	p, err := phhPlayer(f[0], len(gs.Players))
+	if err != nil {
+		return err
+	}
+
+	if f[1] == "sm" {
+		if len(f) < 3 || f[2] == "-" {
+			return nil
+		}
+		shown, err := parseCardRun(f[2])
+		if err != nil {
+			return err
+		}
+		for i, c := range shown {
+			if c != (game.Card{}) && (i >= len(gs.Players[p].HoleCards) || gs.Players[p].HoleCards[i] != c) {
+				return fmt.Errorf("shown cards do not match dealt cards")
+			}
+		}
+		return nil
+	}
+
+	if gs.CurrentPlayerIdx != p {
+		return fmt.Errorf("p%d acted out of turn (expected p%d)", p+1, gs.CurrentPlayerIdx+1)
+	}
+	player := gs.Players[p]
+	action := game.Action{PlayerIdx: p}
+
+	switch f[1] {
+	case "f":
+		action.Type = game.ActionFold
+	case "cc":
+		action.Type = game.ActionCheck
+		if gs.CurrentBet > player.CurrentBet {
+			action.Type = game.ActionCall
+		}
+	case "cbr":
+		if len(f) < 3 {
+			return fmt.Errorf("cbr without amount")
+		}
+		to, err := strconv.Atoi(f[2])
+		if err != nil {
+			return err
+		}
+		action.Type = game.ActionRaise
+		action.Amount = to
+		if to >= player.Stack+player.CurrentBet {
+			action.Type = game.ActionAllIn
+		}
+	default:
+		return fmt.Errorf("unsupported action %q", f[1])
+	}
+
+	if err := gs.ProcessAction(action); err != nil {
+		return err
+	}
+
+	// Without another "d db" line to come, finish the hand: everyone else folded or the
+	// river betting is over.
+	if gs.NeedToAdvanceStreet() && (gs.CountActivePlayers() == 1 || gs.Street == game.StreetRiver) {
+		return gs.AdvanceStreet()
+	}
+	return nil
+}
+
+// phhPlayer converts "p3" to the engine index 2.
+func phhPlayer(s string, n int) (int, error) {
+	if !strings.HasPrefix(s, "p") {
+		return 0, fmt.Errorf("invalid player %q", s)
+	}
+	p, err := strconv.Atoi(s[1:])
+	if err != nil || p < 1 || p > n {
+		return 0, fmt.Errorf("invalid player %q", s)
+	}
+	return p - 1, nil
+}
+
+func intArray(t tomlTable, key string) ([]int, error) {
+	raw, ok := t[key].([]any)
+	if !ok {
+		return nil, fmt.Errorf("missing %s", key)
+	}
+	out := make([]int, len(raw))
+	for i, v := range raw {
+		switch n := v.(type) {
+		case int64:
+			out[i] = int(n)
+		case float64:
+			out[i] = int(n)
+		default:
+			return nil, fmt.Errorf("%s[%d] is not a number", key, i)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package history
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/internal/gametest"
+)
+
+// phhActions returns the actions array of a PHH document, one action per line.
+func phhActions(doc string) string {
+	_, rest, _ := strings.Cut(doc, "actions = [\n")
+	actions, _, _ := strings.Cut(rest, "\n]")
+	return actions
+}
+
+func TestPHHRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		stacks  []int
+		button  int
+		script  []game.Action
+		blinds  string
+		actions []string // Must be among the exported actions
+	}{
+		{
+			name:    "short big blind",
+			stacks:  []int{1000, 1000, 4},
+			button:  0,
+			blinds:  "[5, 10, 0]",
+			actions: []string{`"p3 cc"`}, // The button calls the full big blind
+		},
+		{
+			name:    "short big blind, raised",
+			stacks:  []int{1000, 1000, 4},
+			button:  0,
+			script:  []game.Action{{Type: game.ActionRaise, Amount: 30}},
+			blinds:  "[5, 10, 0]",
+			actions: []string{`"p3 cbr 30"`, `"p1 cc"`},
+		},
+		{
+			name:    "short small blind",
+			stacks:  []int{1000, 3, 1000},
+			button:  0,
+			script:  []game.Action{{Type: game.ActionRaise, Amount: 25}},
+			blinds:  "[5, 10, 0]",
+			actions: []string{`"p3 cbr 25"`, `"p2 cc"`},
+		},
+		{
+			name:    "heads-up short big blind",
+			stacks:  []int{1000, 7},
+			button:  1,
+			blinds:  "[5, 10]",
+			actions: []string{`"p1 cc"`}, // The small blind is left of the button heads-up too
+		},
+		{
+			name:   "all-in preflop",
+			stacks: []int{500, 1000, 1000, 60},
+			button: 3,
+			script: []game.Action{{Type: game.ActionCall}, {Type: game.ActionAllIn}, {Type: game.ActionFold}, {Type: game.ActionCall}},
+			blinds: "[5, 10, 0, 0]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := gametest.PlayHand(t, tt.stacks, tt.button, tt.script...)
+			doc := FormatPHH(gs, gs.CompletedHands()[0], Hero(gs))
+			if !strings.Contains(doc, "blinds_or_straddles = "+tt.blinds+"\n") {
+				t.Errorf("want blinds_or_straddles = %s in\n%s", tt.blinds, doc)
+			}
+			for _, a := range tt.actions {
+				if !strings.Contains(phhActions(doc), a) {
+					t.Errorf("want action %s in\n%s", a, doc)
+				}
+			}
+
+			// ReadPHH checks the replayed finishing stacks against the file's
+			hands, err := ReadPHH(strings.NewReader(doc))
+			if err != nil {
+				t.Fatalf("reading the export back: %v\n%s", err, doc)
+			}
+			replayed := hands[0].State
+			again := FormatPHH(replayed, replayed.CompletedHands()[0], Hero(replayed))
+			if phhActions(again) != phhActions(doc) {
+				t.Errorf("actions changed through a round trip:\n%s\nthen\n%s", phhActions(doc), phhActions(again))
+			}
+		})
+	}
+}
+
+func TestPHHCollectionRoundTrip(t *testing.T) {
+	gs := game.NewGame(game.GameConfig{
+		PlayerNames:    []string{"A", "B", "C"},
+		StartingStacks: []int{200, 35, 12},
+		Stakes:         game.Stakes{SmallBlind: 5, BigBlind: 10},
+		Mode:           game.ModeSimulate,
+	})
+	gs.DetermineButton()
+	for hand := 0; hand < 50 && gs.CountPlayersWithChips() > 1; hand++ {
+		if err := gs.StartHand(); err != nil {
+			t.Fatal(err)
+		}
+		for !gs.IsHandComplete() {
+			if gs.NeedToAdvanceStreet() {
+				if err := gs.AdvanceStreet(); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			// The big stack shoves, everyone else calls
+			action := game.Action{Type: game.ActionCall, PlayerIdx: gs.CurrentPlayerIdx}
+			if gs.CurrentPlayerIdx == 0 {
+				action.Type = game.ActionAllIn
+			}
+			for _, va := range gs.GetValidActions() {
+				if va.Type == game.ActionCheck {
+					action.Type = game.ActionCheck
+				}
+			}
+			if err := gs.ProcessAction(action); err != nil {
+				t.Fatal(err)
+			}
+		}
+		gs.EliminateBrokePlayers()
+	}
+
+	var b strings.Builder
+	if err := WritePHH(&b, gs, gs.CompletedHands(), Hero(gs)); err != nil {
+		t.Fatal(err)
+	}
+	hands, err := ReadPHH(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, b.String())
+	}
+	if len(hands) != len(gs.CompletedHands()) {
+		t.Errorf("read %d hands, wrote %d", len(hands), len(gs.CompletedHands()))
+	}
+}
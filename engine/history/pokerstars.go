@@ -0,0 +1,352 @@
+// Package history exports completed hands from a game's event log (game/events.go) in
+// standard hand history formats so sessions can be loaded into trackers and replayers.
+//
+// This file renders the PokerStars text format read by PokerTracker, Hold'em Manager
+// and most hand replayers. Chips are written without a currency, like play money hands.
+package history
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// Whose hole cards an export deals face up, besides a seat number. Cards tabled at
+// showdown are always written.
+const (
+	AllSeats = -1 // An all-seeing observer, as replayers treat a history with every hand
+	NoSeat   = -2 // A spectator, who sees only what was shown down
+)
+
+// Hero is the seat an export of gs is written for by default: the user in play mode,
+// where the other seats are opponents, otherwise every seat.
+func Hero(gs *game.GameState) int {
+	if gs.Mode == game.ModePlay {
+		return gs.UserSeatIdx
+	}
+	return AllSeats
+}
+
+// dealtFaceUp reports whether an export written for seat shows idx's hole cards.
+func dealtFaceUp(seat, idx int) bool {
+	return seat == AllSeats || seat == idx
+}
+
+// WritePokerStars writes hands, completed hands of gs, in PokerStars format for seat,
+// separated by blank lines as in a PokerStars history file.
+func WritePokerStars(w io.Writer, gs *game.GameState, hands []game.HandRecord, seat int) error {
+	for _, hand := range hands {
+		if _, err := io.WriteString(w, FormatPokerStars(gs, hand, seat)+"\n\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatPokerStars renders a single completed hand as seen by seat.
+func FormatPokerStars(gs *game.GameState, hand game.HandRecord, seat int) string {
+	var b strings.Builder
+	holeCards := hand.HoleCards(len(gs.Players))
+	board := hand.Board()
+	seats := seatNumbers(gs)
+
+	started := gs.GameStartTime
+	if len(hand.Events) > 0 {
+		started = hand.Events[0].Time
+	}
+
+	fmt.Fprintf(&b, "PokerStars Hand #%d%04d: Hold'em No Limit (%d/%d) - %s\n",
+		gs.GameStartTime.Unix(), hand.Number, gs.Stakes.SmallBlind, gs.Stakes.BigBlind, formatTime(started))
+	fmt.Fprintf(&b, "Table '%s' %d-max Seat #%d is the button\n", gs.ID, len(gs.Players), seats[hand.Button])
+
+	for i, p := range gs.Players {
+		if holeCards[i] == nil {
+			continue // Eliminated, not dealt in
+		}
+		fmt.Fprintf(&b, "Seat %d: %s (%d in chips)\n", seats[i], p.Name, hand.Stacks[i])
+	}
+
+	var (
+		contributed     = make([]int, len(gs.Players))
+		streetBets      = make([]int, len(gs.Players))
+		currentBet      = 0
+		foldStreet      = make(map[int]game.Street)
+		blinds          = 0
+		dealt           []game.Card
+		holeCardsHeader = false
+	)
+
+	for _, ev := range hand.Events {
+		name := ""
+		if ev.PlayerIdx >= 0 && ev.PlayerIdx < len(gs.Players) {
+			name = gs.Players[ev.PlayerIdx].Name
+		}
+
+		switch ev.Type {
+		case game.EventPost:
+			blindName, blind := "small blind", gs.Stakes.SmallBlind
+			if blinds > 0 {
+				blindName, blind = "big blind", gs.Stakes.BigBlind
+			}
+			blinds++
+			allIn := ""
+			if ev.Amount < blind {
+				allIn = " and is all-in"
+			}
+			fmt.Fprintf(&b, "%s: posts %s %d%s\n", name, blindName, ev.Amount, allIn)
+			contributed[ev.PlayerIdx] += ev.Amount
+			streetBets[ev.PlayerIdx] += ev.Amount
+			// Calling a short big blind still takes the full big blind
+			currentBet = gs.Stakes.BigBlind
+
+		case game.EventDeal:
+			if !holeCardsHeader {
+				b.WriteString("*** HOLE CARDS ***\n")
+				holeCardsHeader = true
+			}
+			if !dealtFaceUp(seat, ev.PlayerIdx) {
+				continue
+			}
+			fmt.Fprintf(&b, "Dealt to %s [%s]\n", name, joinCards(ev.Cards))
+
+		case game.EventAction:
+			if ev.Resolved == nil {
+				continue
+			}
+			line := actionLine(*ev.Resolved, ev.Amount, streetBets[ev.PlayerIdx], currentBet)
+			fmt.Fprintf(&b, "%s: %s\n", name, line)
+			if ev.Resolved.Type == game.ActionFold {
+				foldStreet[ev.PlayerIdx] = ev.Street
+			}
+			contributed[ev.PlayerIdx] += ev.Amount
+			streetBets[ev.PlayerIdx] += ev.Amount
+			currentBet = max(currentBet, streetBets[ev.PlayerIdx])
+
+		case game.EventStreet:
+			for i := range streetBets {
+				streetBets[i] = 0
+			}
+			currentBet = 0
+			// A street event can deal several streets at once when players are all-in.
+			for _, c := range ev.Cards {
+				dealt = append(dealt, c)
+				switch len(dealt) {
+				case 3:
+					fmt.Fprintf(&b, "*** %s *** [%s]\n", strings.ToUpper(streetTitles[game.StreetFlop]), joinCards(dealt))
+				case 4, 5:
+					fmt.Fprintf(&b, "*** %s *** [%s] [%s]\n", strings.ToUpper(streetTitles[game.Street(len(dealt)-2)]),
+						joinCards(dealt[:len(dealt)-1]), dealt[len(dealt)-1])
+				}
+			}
+		}
+	}
+
+	// Final outcome
+	total := 0
+	for _, c := range contributed {
+		total += c
+	}
+	inHand := playersAtEnd(holeCards, foldStreet)
+	showdown := len(inHand) > 1
+
+	collected := make(map[int]int)
+	for _, ev := range hand.Events {
+		if ev.Type == game.EventAward && showdown {
+			collected[ev.PlayerIdx] += ev.Amount
+		}
+	}
+	awarded := 0
+	for _, amt := range collected {
+		awarded += amt
+	}
+	if !showdown && len(inHand) == 1 {
+		uncalled := uncalledBet(contributed, inHand[0])
+		if uncalled > 0 {
+			fmt.Fprintf(&b, "Uncalled bet (%d) returned to %s\n", uncalled, gs.Players[inHand[0]].Name)
+		}
+		collected[inHand[0]] = total - uncalled
+		awarded = total - uncalled
+	} else if showdown && awarded < total {
+		// Side pot only one player was eligible for
+		top := inHand[0]
+		for _, idx := range inHand {
+			if contributed[idx] > contributed[top] {
+				top = idx
+			}
+		}
+		fmt.Fprintf(&b, "Uncalled bet (%d) returned to %s\n", total-awarded, gs.Players[top].Name)
+	}
+
+	descs := make(map[int]string)
+	if showdown {
+		b.WriteString("*** SHOW DOWN ***\n")
+		for _, idx := range inHand {
+			result := game.EvaluateHand(append(append([]game.Card{}, holeCards[idx]...), board...))
+			descs[idx] = game.GetHandDescription(result)
+			fmt.Fprintf(&b, "%s: shows [%s] (%s)\n", gs.Players[idx].Name, joinCards(holeCards[idx]), descs[idx])
+		}
+	}
+	for _, idx := range sortedKeys(collected) {
+		fmt.Fprintf(&b, "%s collected %d from pot\n", gs.Players[idx].Name, collected[idx])
+	}
+
+	b.WriteString("*** SUMMARY ***\n")
+	fmt.Fprintf(&b, "Total pot %d | Rake 0\n", awarded)
+	if len(board) > 0 {
+		fmt.Fprintf(&b, "Board [%s]\n", joinCards(board))
+	}
+
+	sbIdx, bbIdx := blindSeats(hand)
+	for i, p := range gs.Players {
+		if holeCards[i] == nil {
+			continue
+		}
+		role := ""
+		switch i {
+		case hand.Button:
+			role = " (button)"
+		case sbIdx:
+			role = " (small blind)"
+		case bbIdx:
+			role = " (big blind)"
+		}
+
+		var outcome string
+		if street, folded := foldStreet[i]; folded {
+			if street == game.StreetPreflop {
+				outcome = "folded before Flop"
+			} else {
+				outcome = "folded on the " + streetTitles[street]
+			}
+			if contributed[i] == 0 {
+				outcome += " (didn't bet)"
+			}
+		} else if showdown {
+			if won, ok := collected[i]; ok {
+				outcome = fmt.Sprintf("showed [%s] and won (%d) with %s", joinCards(holeCards[i]), won, descs[i])
+			} else {
+				outcome = fmt.Sprintf("showed [%s] and lost with %s", joinCards(holeCards[i]), descs[i])
+			}
+		} else {
+			outcome = fmt.Sprintf("collected (%d)", collected[i])
+		}
+		fmt.Fprintf(&b, "Seat %d: %s%s %s\n", seats[i], p.Name, role, outcome)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+var streetTitles = map[game.Street]string{
+	game.StreetFlop:  "Flop",
+	game.StreetTurn:  "Turn",
+	game.StreetRiver: "River",
+}
+
+// formatTime uses Eastern Time like PokerStars, falling back to UTC when the zone
+// database is unavailable (e.g. minimal containers).
+func formatTime(t time.Time) string {
+	const layout = "2006/01/02 15:04:05"
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		return t.In(loc).Format(layout) + " ET"
+	}
+	return t.UTC().Format(layout) + " UTC"
+}
+
+// actionLine renders a resolved action. chips is what the player put in with this action,
+// streetBet what they had already put in this street, and currentBet the bet to match.
+func actionLine(a game.Action, chips, streetBet, currentBet int) string {
+	switch a.Type {
+	case game.ActionFold:
+		return "folds"
+	case game.ActionCheck:
+		return "checks"
+	case game.ActionCall:
+		return fmt.Sprintf("calls %d", chips)
+	case game.ActionRaise:
+		if currentBet == 0 {
+			return fmt.Sprintf("bets %d", a.Amount)
+		}
+		return fmt.Sprintf("raises %d to %d", a.Amount-currentBet, a.Amount)
+	case game.ActionAllIn:
+		total := streetBet + chips
+		switch {
+		case total <= currentBet:
+			return fmt.Sprintf("calls %d and is all-in", chips)
+		case currentBet == 0:
+			return fmt.Sprintf("bets %d and is all-in", chips)
+		default:
+			return fmt.Sprintf("raises %d to %d and is all-in", total-currentBet, total)
+		}
+	}
+	return strings.ToLower(a.Type.String())
+}
+
+// seatNumbers maps player index to the 1-based seat number used in the export.
+func seatNumbers(gs *game.GameState) []int {
+	seats := make([]int, len(gs.Players))
+	for i, p := range gs.Players {
+		seats[i] = p.SeatPosition + 1
+	}
+	return seats
+}
+
+func blindSeats(hand game.HandRecord) (sb, bb int) {
+	sb, bb = -1, -1
+	for _, ev := range hand.Events {
+		if ev.Type != game.EventPost {
+			continue
+		}
+		if sb < 0 {
+			sb = ev.PlayerIdx
+		} else if bb < 0 {
+			bb = ev.PlayerIdx
+		}
+	}
+	return sb, bb
+}
+
+// playersAtEnd returns the dealt-in players who never folded.
+func playersAtEnd(holeCards [][]game.Card, folded map[int]game.Street) []int {
+	var idxs []int
+	for i, cards := range holeCards {
+		if cards == nil {
+			continue
+		}
+		if _, ok := folded[i]; !ok {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+// uncalledBet is how much more the winner put in than anyone else.
+func uncalledBet(contributed []int, winner int) int {
+	second := 0
+	for i, c := range contributed {
+		if i != winner && c > second {
+			second = c
+		}
+	}
+	return max(contributed[winner]-second, 0)
+}
+
+func joinCards(cards []game.Card) string {
+	strs := make([]string, len(cards))
+	for i, c := range cards {
+		strs[i] = c.String()
+	}
+	return strings.Join(strs, " ")
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+	"github.com/rizzwareengineer/no-LLMit/engine/internal/gametest"
+)
+
+func TestPokerStarsShortBigBlind(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []game.Action
+		lines  []string
+	}{
+		{
+			name:  "called",
+			lines: []string{"C: posts big blind 4 and is all-in\n", "A: calls 10\n", "B: calls 5\n"},
+		},
+		{
+			name:   "raised",
+			script: []game.Action{{Type: game.ActionRaise, Amount: 30}},
+			lines:  []string{"A: raises 20 to 30\n", "B: calls 25\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := gametest.PlayHand(t, []int{1000, 1000, 4}, 0, tt.script...)
+			text := FormatPokerStars(gs, gs.CompletedHands()[0], Hero(gs))
+			for _, line := range tt.lines {
+				if !strings.Contains(text, line) {
+					t.Errorf("want %q in\n%s", line, text)
+				}
+			}
+		})
+	}
+}
+
+// TestPokerStarsHands exports three-handed hands where A is the button, B the small
+// blind and C the big blind. Every seat is dealt a heart flush on the 9h Th Jh Kh 2d
+// board: A's 4h 7h is best, then C's 3h 6h, then B's 2h 5h.
+func TestPokerStarsHands(t *testing.T) {
+	allIn := game.Action{Type: game.ActionAllIn}
+	tests := []struct {
+		name   string
+		stacks []int
+		script []game.Action
+		seat   int
+		lines  []string // In order
+		absent []string
+	}{
+		{
+			name:   "showdown",
+			stacks: []int{1000, 1000, 1000},
+			script: []game.Action{{Type: game.ActionRaise, Amount: 30}, {Type: game.ActionFold}, {Type: game.ActionCall},
+				{Type: game.ActionCheck}, {Type: game.ActionRaise, Amount: 40}, {Type: game.ActionCall}},
+			seat: AllSeats,
+			lines: []string{
+				"Seat #1 is the button\n",
+				"Seat 1: A (1000 in chips)\n", "Seat 2: B (1000 in chips)\n", "Seat 3: C (1000 in chips)\n",
+				"B: posts small blind 5\n", "C: posts big blind 10\n",
+				"*** HOLE CARDS ***\n", "Dealt to A [4h 7h]\n", "Dealt to B [2h 5h]\n", "Dealt to C [3h 6h]\n",
+				"A: raises 20 to 30\n", "B: folds\n", "C: calls 20\n",
+				"*** FLOP *** [9h Th Jh]\n", "C: checks\n", "A: bets 40\n", "C: calls 40\n",
+				"*** TURN *** [9h Th Jh] [Kh]\n", "C: checks\n", "A: checks\n",
+				"*** RIVER *** [9h Th Jh Kh] [2d]\n", "C: checks\n", "A: checks\n",
+				"*** SHOW DOWN ***\n", "A: shows [4h 7h] (Flush, K high)\n", "C: shows [3h 6h] (Flush, K high)\n",
+				"A collected 145 from pot\n",
+				"*** SUMMARY ***\n", "Total pot 145 | Rake 0\n", "Board [9h Th Jh Kh 2d]\n",
+				"Seat 1: A (button) showed [4h 7h] and won (145) with Flush, K high\n",
+				"Seat 2: B (small blind) folded before Flop\n",
+				"Seat 3: C (big blind) showed [3h 6h] and lost with Flush, K high",
+			},
+			absent: []string{"Uncalled bet", "B: shows"},
+		},
+		{
+			name:   "side pot and uncalled bet",
+			stacks: []int{200, 1000, 500},
+			script: []game.Action{allIn, allIn, allIn},
+			seat:   AllSeats,
+			lines: []string{
+				"A: raises 190 to 200 and is all-in\n",
+				"B: raises 800 to 1000 and is all-in\n",
+				"C: calls 490 and is all-in\n",
+				"*** FLOP *** [9h Th Jh]\n", "*** TURN *** [9h Th Jh] [Kh]\n", "*** RIVER *** [9h Th Jh Kh] [2d]\n",
+				"Uncalled bet (500) returned to B\n",
+				"*** SHOW DOWN ***\n",
+				"A collected 600 from pot\n", "C collected 600 from pot\n",
+				"*** SUMMARY ***\n", "Total pot 1200 | Rake 0\n",
+				"Seat 1: A (button) showed [4h 7h] and won (600) with Flush, K high\n",
+				"Seat 2: B (small blind) showed [2h 5h] and lost with Flush, K high\n",
+				"Seat 3: C (big blind) showed [3h 6h] and won (600) with Flush, K high",
+			},
+		},
+		{
+			name:   "ends on a fold",
+			stacks: []int{1000, 1000, 1000},
+			script: []game.Action{{Type: game.ActionRaise, Amount: 30}, {Type: game.ActionFold}, {Type: game.ActionFold}},
+			seat:   1,
+			lines: []string{
+				"*** HOLE CARDS ***\n", "Dealt to B [2h 5h]\n",
+				"A: raises 20 to 30\n", "B: folds\n", "C: folds\n",
+				"Uncalled bet (20) returned to A\n",
+				"A collected 25 from pot\n",
+				"*** SUMMARY ***\n", "Total pot 25 | Rake 0\n",
+				"Seat 1: A (button) collected (25)\n",
+				"Seat 2: B (small blind) folded before Flop\n",
+				"Seat 3: C (big blind) folded before Flop",
+			},
+			absent: []string{"Dealt to A", "Dealt to C", "*** FLOP ***", "*** SHOW DOWN ***", "Board ["},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := gametest.PlayHand(t, tt.stacks, 0, tt.script...)
+			text := FormatPokerStars(gs, gs.CompletedHands()[0], tt.seat)
+			rest := text
+			for _, line := range tt.lines {
+				i := strings.Index(rest, line)
+				if i < 0 {
+					t.Fatalf("want %q, in order, in\n%s", line, text)
+				}
+				rest = rest[i+len(line):]
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(text, s) {
+					t.Errorf("want no %q in\n%s", s, text)
+				}
+			}
+		})
+	}
+}
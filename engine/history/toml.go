@@ -0,0 +1,203 @@
+// This file is a minimal TOML reader covering what PHH files use: comments, [table]
+// headers, and key = value pairs whose values are strings, integers, floats, booleans
+// or (possibly multi-line) arrays of those. It is not a general TOML implementation.
+package history
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tomlTable maps keys to string, int64, float64, bool or []any values.
+type tomlTable map[string]any
+
+// tomlDocument is a parsed file: top-level keys plus named tables in file order.
+type tomlDocument struct {
+	Root       tomlTable
+	Tables     map[string]tomlTable
+	TableOrder []string
+}
+
+func parseTOML(src string) (*tomlDocument, error) {
+	p := &tomlParser{src: src, line: 1}
+	doc := &tomlDocument{Root: tomlTable{}, Tables: map[string]tomlTable{}}
+	current := doc.Root
+
+	for {
+		p.skipSpaceAndComments(true)
+		if p.eof() {
+			return doc, nil
+		}
+
+		if p.peek() == '[' {
+			p.pos++
+			name := strings.TrimSpace(p.until(']'))
+			if !p.consume(']') {
+				return nil, p.errorf("unterminated table header")
+			}
+			name = strings.Trim(name, `"'`)
+			if _, dup := doc.Tables[name]; dup {
+				return nil, p.errorf("duplicate table [%s]", name)
+			}
+			current = tomlTable{}
+			doc.Tables[name] = current
+			doc.TableOrder = append(doc.TableOrder, name)
+			continue
+		}
+
+		key := strings.TrimSpace(p.until('='))
+		if key == "" || !p.consume('=') {
+			return nil, p.errorf("expected key = value")
+		}
+		key = strings.Trim(key, `"'`)
+		p.skipSpaceAndComments(false)
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		current[key] = value
+	}
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) eof() bool  { return p.pos >= len(p.src) }
+func (p *tomlParser) peek() byte { return p.src[p.pos] }
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("toml line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) consume(c byte) bool {
+	if !p.eof() && p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// until returns the text up to (not including) c or the end of the line.
+func (p *tomlParser) until(c byte) string {
+	start := p.pos
+	for !p.eof() && p.peek() != c && p.peek() != '\n' {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *tomlParser) skipSpaceAndComments(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.line++
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) value() (any, error) {
+	if p.eof() {
+		return nil, p.errorf("missing value")
+	}
+
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.str()
+	case c == '[':
+		return p.array()
+	default:
+		start := p.pos
+		for !p.eof() && !strings.ContainsRune(",]#\n\r", rune(p.peek())) {
+			p.pos++
+		}
+		raw := strings.TrimSpace(p.src[start:p.pos])
+		raw = strings.ReplaceAll(raw, "_", "")
+		switch raw {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return i, nil
+		}
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return f, nil
+		}
+		return nil, p.errorf("unsupported value %q", raw)
+	}
+}
+
+func (p *tomlParser) str() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("newline in string")
+		case c == '\\' && quote == '"' && !p.eof():
+			esc := p.peek()
+			p.pos++
+			switch esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(esc)
+			default:
+				return "", p.errorf("unsupported escape \\%c", esc)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) array() ([]any, error) {
+	p.pos++ // [
+	values := []any{}
+	for {
+		p.skipSpaceAndComments(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.consume(']') {
+			return values, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		p.skipSpaceAndComments(true)
+		if !p.consume(',') && (p.eof() || p.peek() != ']') {
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,60 @@
+// Package gametest plays hands with known cards for tests in other packages.
+package gametest
+
+import (
+	"testing"
+
+	"github.com/rizzwareengineer/no-LLMit/engine/game"
+)
+
+// OrderedDeck is a fresh deck, twos to aces of hearts first, then each other suit.
+func OrderedDeck() []game.Card {
+	var deck []game.Card
+	for suit := game.Hearts; suit <= game.Spades; suit++ {
+		for rank := game.Two; rank <= game.Ace; rank++ {
+			deck = append(deck, game.Card{Rank: rank, Suit: suit})
+		}
+	}
+	return deck
+}
+
+// PlayHand plays one hand of a fresh 5/10 game with the given stacks and button, dealt
+// from OrderedDeck. Each seat to act takes the next of script, then checks or calls once
+// it runs out.
+func PlayHand(t testing.TB, stacks []int, button int, script ...game.Action) *game.GameState {
+	t.Helper()
+	names := []string{"A", "B", "C", "D"}[:len(stacks)]
+	gs, err := game.Replay(game.GameConfig{
+		PlayerNames:    names,
+		StartingStacks: stacks,
+		Stakes:         game.Stakes{SmallBlind: 5, BigBlind: 10},
+		Mode:           game.ModeSimulate,
+	}, []game.Event{{Type: game.EventHandStart, HandNumber: 1, Button: button, Deck: OrderedDeck()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for !gs.IsHandComplete() {
+		if gs.NeedToAdvanceStreet() {
+			if err := gs.AdvanceStreet(); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		action := game.Action{Type: game.ActionCall}
+		if len(script) > 0 {
+			action, script = script[0], script[1:]
+		} else {
+			for _, va := range gs.GetValidActions() {
+				if va.Type == game.ActionCheck {
+					action.Type = game.ActionCheck
+				}
+			}
+		}
+		action.PlayerIdx = gs.CurrentPlayerIdx
+		if err := gs.ProcessAction(action); err != nil {
+			t.Fatalf("%s for seat %d: %v", action.Type, action.PlayerIdx, err)
+		}
+	}
+	return gs
+}
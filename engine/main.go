@@ -2,9 +2,12 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/rizzwareengineer/no-LLMit/engine/api"
 )
@@ -26,8 +29,12 @@ func main() {
 	log.Println("Health check: http://localhost:" + strconv.Itoa(port) + "/health")
 	log.Println("")
 
+	// Stop on Ctrl-C or when the host redeploys, once queued dataset records are saved
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	server := api.NewServer()
-	if err := server.Start(port); err != nil {
+	if err := server.Start(ctx, port); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -10,6 +10,7 @@ from fastapi.middleware.cors import CORSMiddleware
 
 from schemas import DecisionRequest, DecisionResponse
 from providers.huggingface import get_decision
+from prompts import system_prompt
 from usage import tracker, SPECTATE_DAILY_REQUESTS
 
 load_dotenv()
@@ -52,6 +53,12 @@ def reset_usage():
     return {"status": "reset", "usage": tracker.get_summary()}
 
 
+@app.get("/system-prompt")
+def get_system_prompt():
+    """The system prompt sent with every decision, for building fine-tuning examples."""
+    return {"system_prompt": system_prompt}
+
+
 @app.post("/decide", response_model=DecisionResponse)
 def decide(request: DecisionRequest):
     """Get LLM decision for the current game state."""
@@ -15,13 +15,13 @@ const EXAMPLE_INPUT = {
   communityCards: ["Kd", "7c", "2s"],
   pot: 90,
   actionsThisHand: [
-    { player: "Claude", action: "post", amount: 5 },
-    { player: "GPT-4", action: "post", amount: 10 },
-    { player: "Qwen", action: "RAISE", amount: 30 },
-    { player: "Claude", action: "CALL", amount: 25 },
-    { player: "GPT-4", action: "CALL", amount: 20 },
-    { player: "Claude", action: "CHECK" },
-    { player: "GPT-4", action: "CHECK" }
+    { player: "Claude", action: "post", amount: 5, street: "preflop", seat: 1, position: "SB", potBefore: 0, stackBefore: 500 },
+    { player: "GPT-4", action: "post", amount: 10, street: "preflop", seat: 2, position: "BB", potBefore: 5, stackBefore: 500 },
+    { player: "Qwen", action: "RAISE", amount: 30, street: "preflop", seat: 3, position: "BTN", potBefore: 15, stackBefore: 500 },
+    { player: "Claude", action: "CALL", amount: 25, street: "preflop", seat: 1, position: "SB", potBefore: 45, stackBefore: 495 },
+    { player: "GPT-4", action: "CALL", amount: 20, street: "preflop", seat: 2, position: "BB", potBefore: 70, stackBefore: 490 },
+    { player: "Claude", action: "CHECK", street: "flop", seat: 1, position: "SB", potBefore: 90, stackBefore: 470 },
+    { player: "GPT-4", action: "CHECK", street: "flop", seat: 2, position: "BB", potBefore: 90, stackBefore: 470 }
   ],
   previousHands: [],
   validActions: [
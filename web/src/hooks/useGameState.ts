@@ -31,6 +31,7 @@ interface LLMDecision {
   action: string;
   amount: number;
   reason: string;
+  promptTokens?: number; // Estimated prompt size
 }
 
 // Display phases for each LLM turn
@@ -124,6 +125,8 @@ export function useGameState(options: UseGameStateOptions = {}): UseGameStateRet
   const phaseTimerRef = useRef<NodeJS.Timeout | null>(null);
   const shotClockTimerRef = useRef<NodeJS.Timeout | null>(null);
   const shotClockIntervalRef = useRef<NodeJS.Timeout | null>(null);
+  // The turn the last action was sent for; a resend for it reuses its action ID
+  const sentTurnRef = useRef<{ handNumber: number; turnToken: number; actionId: string } | null>(null);
 
   // Calculate reasoning duration based on actual typing speed
   // TypewriterText types at 25ms per character, so match that + small buffer
@@ -337,6 +340,9 @@ export function useGameState(options: UseGameStateOptions = {}): UseGameStateRet
 
       ws.on('error', (payload) => {
         const err = payload as ErrorPayload;
+        if (err.code === 'duplicate_action') {
+          return; // The first copy already went through
+        }
         setError(err.message);
         setIsLoading(false);
         if (err.message.includes('No game found') || err.message.includes('game not found')) {
@@ -459,15 +465,32 @@ export function useGameState(options: UseGameStateOptions = {}): UseGameStateRet
       return;
     }
 
+    let turn = sentTurnRef.current;
+    if (actionRequired && turn?.turnToken !== actionRequired.turnToken) {
+      turn = {
+        handNumber: actionRequired.handNumber,
+        turnToken: actionRequired.turnToken,
+        actionId: crypto.randomUUID(),
+      };
+      sentTurnRef.current = turn;
+    }
+    if (!turn) {
+      setError('Not your turn');
+      return;
+    }
+
     const payload: ActionPayload = {
       playerIdx: gameState.currentPlayerIdx,
       action,
       amount,
+      actionId: turn.actionId,
+      handNumber: turn.handNumber,
+      turnToken: turn.turnToken,
     };
 
     setActionRequired(null);
     wsRef.current.action(payload);
-  }, [gameState]);
+  }, [gameState, actionRequired]);
 
   const clearError = useCallback(() => {
     setError(null);
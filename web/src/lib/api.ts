@@ -9,6 +9,10 @@ export type MessageType =
   | 'get_state'
   | 'pause'
   | 'resume'
+  | 'join'
+  | 'rejoin'
+  | 'resync_from'
+  | 'end_game'
   | 'game_state'
   | 'error'
   | 'hand_start'
@@ -20,7 +24,14 @@ export type MessageType =
   | 'paused'
   | 'resumed'
   | 'button_card'
-  | 'button_winner';
+  | 'button_winner'
+  | 'stats_update'
+  | 'joined'
+  | 'session'
+  | 'missed_events'
+  | 'auto_action'
+  | 'clock_tick'
+  | 'game_over';
 
 export interface ClientMessage {
   type: MessageType;
@@ -30,6 +41,7 @@ export interface ClientMessage {
 export interface ServerMessage {
   type: MessageType;
   payload?: unknown;
+  seq?: number; // Per game, on broadcasts; a gap means messages were missed, see ResyncPayload
 }
 
 export interface NewGamePayload {
@@ -39,14 +51,132 @@ export interface NewGamePayload {
   bigBlind: number;
   mode: 'simulate' | 'play' | 'test';
   userSeatIdx?: number;
+  prompt?: PromptConfig;
+  bots?: Record<number, BotName>; // Seat -> in-process bot, see engine/decider
+  spectatorDelayMs?: number;      // Spectators see every card this late; 0 shows them only shown-down hands
+  shotClockMs?: number;           // Time each seat gets to act; 0 for no clock
+  timeBankMs?: number;            // Extra time per seat, refilled every level
+  timeBankLevelHands?: number;    // Hands per level (default 10)
+}
+
+export type BotName = 'random' | 'station' | 'tag' | 'pushfold';
+
+// Optional LLM prompt sections, see engine/game/llm.go
+export interface PromptConfig {
+  hud?: {
+    enabled: boolean;
+    stats?: string[];      // Defaults to vpip, pfr, af
+    minHands?: number;
+    hideHistory?: boolean; // Send the HUD instead of previousHands
+  };
+  history?: {
+    strategy?: 'full' | 'last_n' | 'involved' | 'compact' | 'summary';
+    lastN?: number;        // Hands kept in full by last_n and summary (default 10)
+    maxTokens?: number;    // Drop the oldest hands until the prompt fits
+  };
+  context?: {              // Each enabled field is added to the prompt's context section
+    amountToCall?: boolean;
+    potOdds?: boolean;
+    spr?: boolean;
+    effectiveStacks?: boolean;
+    street?: boolean;
+    position?: boolean;
+    playersToAct?: boolean;
+  };
 }
 
 export interface ActionPayload {
   playerIdx: number;
   action: 'fold' | 'check' | 'call' | 'raise' | 'all-in';
   amount?: number;
+  // Guards against double clicks and late clicks
+  actionId?: string;   // One per turn; a resend with the same ID is rejected with code 'duplicate_action'
+  handNumber?: number;
+  turnToken: number;   // Required, from ActionRequiredPayload; a stale one is rejected with code 'stale_action'
+}
+
+export interface PausePayload {
+  hard?: boolean; // Cancel the decision in flight; the seat is asked again on resume
+}
+
+// Spectate a game by ID; the server answers with 'joined' and then broadcasts its messages
+export interface JoinPayload {
+  gameId: string;
+  token?: string; // Admin token: every card, no delay
+}
+
+export interface JoinedPayload {
+  gameId: string;
+  spectators: number;
+  admin: boolean;
+  delayMs: number;
+}
+
+// Replay broadcasts after `from`; if they are gone the server sends a fresh 'game_state'
+export interface ResyncPayload {
+  from: number; // Last seq received
+}
+
+// Tokens to take a game back after a disconnect, sent after new_game and rejoin
+export interface SessionPayload {
+  gameId: string;
+  tokens: Record<number, string>; // Seat -> token; seat -1 in simulate mode
+}
+
+// Rebind this connection as the game's owner; the server replies with 'session',
+// 'missed_events' and 'game_state'
+export interface RejoinPayload {
+  gameId: string;
+  token: string;
+  lastEvent: number; // GameEvent.seq of the last event seen, not a message seq; -1 replays the whole game (send -1 after an undo or rewind)
+}
+
+// Game log entries after lastEvent, see engine/game/events.go. Hidden cards are omitted.
+export interface MissedEventsPayload {
+  events: GameEvent[];
+}
+
+export interface GameEvent {
+  seq: number;
+  type: 'hand_start' | 'post' | 'deal' | 'action' | 'street' | 'award';
+  handNumber: number;
+  time: string;
+  street: number;
+  playerIdx: number;
+  amount?: number;
+  cards?: { rank: number; suit: number }[];
+  handDesc?: string;
+  potNumber?: number;
 }
 
+// A seat was checked or folded for: its owner disconnected 30s ago, or its clock ran out
+export interface AutoActionPayload {
+  playerIdx: number;
+  action: string;
+  reason: 'disconnected' | 'timeout';
+}
+
+// Time left for the seat to act, sent every second while its clock runs
+export interface ClockTickPayload {
+  playerIdx: number;
+  remainingMs: number; // Shot clock
+  timeBankMs: number;  // Used once the shot clock runs out
+}
+
+// Sent when a game finishes (one player has every chip, or end_game) or is abandoned
+export interface GameResultPayload {
+  gameId: string;
+  state: 'finished' | 'abandoned';
+  reason: 'won' | 'ended' | 'idle';
+  mode: string;
+  hands: number;
+  startedAt: string;
+  endedAt: string;
+  players: { seat: number; name: string; stack: number; net: number }[];
+}
+
+export type GameStatus = 'lobby' | 'running' | 'paused' | 'finished' | 'abandoned';
+
 export interface ButtonCardPayload {
   playerIdx: number;
   playerName: string;
@@ -98,6 +228,7 @@ export interface GameState {
   buttonIdx: number;
   players: PlayerState[];
   validActions?: ValidAction[];
+  turnToken?: number; // While a seat is to act; echo in ActionPayload
   winners?: Winner[];
   mode: string;
   stakes: {
@@ -112,6 +243,8 @@ export interface ActionRequiredPayload {
   playerName: string;
   validActions: ValidAction[];
   timeoutMs?: number;
+  handNumber: number;
+  turnToken: number;  // Echo in ActionPayload
 }
 
 export interface HandCompletePayload {
@@ -119,8 +252,29 @@ export interface HandCompletePayload {
   handNumber: number;
 }
 
+export interface PlayerStats {
+  name: string;
+  vpip: number;           // Percentages are 0-100
+  pfr: number;
+  threeBet: number;
+  foldToThreeBet: number;
+  cBet: number;
+  af: number;             // Postflop aggression factor
+  wtsd: number;
+  wsd: number;            // W$SD
+  bbPer100: number;
+  counts: { hands: number } & Record<string, number>;
+}
+
+export interface StatsUpdatePayload {
+  gameId: string;
+  handNumber: number;
+  players: PlayerStats[]; // Indexed by seat
+}
+
 export interface ErrorPayload {
   message: string;
+  code?: 'stale_action' | 'duplicate_action';
 }
 
 // WebSocket wrapper class